@@ -0,0 +1,1405 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: sadis.proto
+
+package sadis
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Kind is the type of a SADIS entry
+type Kind int32
+
+const (
+	Kind_OLT               Kind = 0
+	Kind_ONU               Kind = 1
+	Kind_BANDWIDTH_PROFILE Kind = 2
+)
+
+var Kind_name = map[int32]string{
+	0: "OLT",
+	1: "ONU",
+	2: "BANDWIDTH_PROFILE",
+}
+
+var Kind_value = map[string]int32{
+	"OLT":               0,
+	"ONU":               1,
+	"BANDWIDTH_PROFILE": 2,
+}
+
+func (x Kind) String() string {
+	return proto.EnumName(Kind_name, int32(x))
+}
+
+func (Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{0}
+}
+
+type Event_Type int32
+
+const (
+	Event_ADD    Event_Type = 0
+	Event_UPDATE Event_Type = 1
+	Event_DELETE Event_Type = 2
+)
+
+var Event_Type_name = map[int32]string{
+	0: "ADD",
+	1: "UPDATE",
+	2: "DELETE",
+}
+
+var Event_Type_value = map[string]int32{
+	"ADD":    0,
+	"UPDATE": 1,
+	"DELETE": 2,
+}
+
+func (x Event_Type) String() string {
+	return proto.EnumName(Event_Type_name, int32(x))
+}
+
+func (Event_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{11, 0}
+}
+
+// Owner is the source that published an entry (eg: a BBSim pod)
+type Owner struct {
+	Source               string   `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Uid                  string   `protobuf:"bytes,4,opt,name=uid,proto3" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Owner) Reset()         { *m = Owner{} }
+func (m *Owner) String() string { return proto.CompactTextString(m) }
+func (*Owner) ProtoMessage()    {}
+func (*Owner) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{0}
+}
+
+func (m *Owner) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Owner.Unmarshal(m, b)
+}
+func (m *Owner) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Owner.Marshal(b, m, deterministic)
+}
+func (m *Owner) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Owner.Merge(m, src)
+}
+func (m *Owner) XXX_Size() int {
+	return xxx_messageInfo_Owner.Size(m)
+}
+func (m *Owner) XXX_DiscardUnknown() {
+	xxx_messageInfo_Owner.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Owner proto.InternalMessageInfo
+
+func (m *Owner) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Owner) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Owner) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Owner) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+type OltEntry struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	HardwareIdentifier   string   `protobuf:"bytes,2,opt,name=hardware_identifier,json=hardwareIdentifier,proto3" json:"hardware_identifier,omitempty"`
+	IpAddress            string   `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	NasId                string   `protobuf:"bytes,4,opt,name=nas_id,json=nasId,proto3" json:"nas_id,omitempty"`
+	UplinkPort           int32    `protobuf:"varint,5,opt,name=uplink_port,json=uplinkPort,proto3" json:"uplink_port,omitempty"`
+	NniDhcpTrapVid       int32    `protobuf:"varint,6,opt,name=nni_dhcp_trap_vid,json=nniDhcpTrapVid,proto3" json:"nni_dhcp_trap_vid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OltEntry) Reset()         { *m = OltEntry{} }
+func (m *OltEntry) String() string { return proto.CompactTextString(m) }
+func (*OltEntry) ProtoMessage()    {}
+func (*OltEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{1}
+}
+
+func (m *OltEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OltEntry.Unmarshal(m, b)
+}
+func (m *OltEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OltEntry.Marshal(b, m, deterministic)
+}
+func (m *OltEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OltEntry.Merge(m, src)
+}
+func (m *OltEntry) XXX_Size() int {
+	return xxx_messageInfo_OltEntry.Size(m)
+}
+func (m *OltEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_OltEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OltEntry proto.InternalMessageInfo
+
+func (m *OltEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *OltEntry) GetHardwareIdentifier() string {
+	if m != nil {
+		return m.HardwareIdentifier
+	}
+	return ""
+}
+
+func (m *OltEntry) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *OltEntry) GetNasId() string {
+	if m != nil {
+		return m.NasId
+	}
+	return ""
+}
+
+func (m *OltEntry) GetUplinkPort() int32 {
+	if m != nil {
+		return m.UplinkPort
+	}
+	return 0
+}
+
+func (m *OltEntry) GetNniDhcpTrapVid() int32 {
+	if m != nil {
+		return m.NniDhcpTrapVid
+	}
+	return 0
+}
+
+type UniTag struct {
+	UniTagMatch                int32    `protobuf:"varint,1,opt,name=uni_tag_match,json=uniTagMatch,proto3" json:"uni_tag_match,omitempty"`
+	PonCTag                    int32    `protobuf:"varint,2,opt,name=pon_c_tag,json=ponCTag,proto3" json:"pon_c_tag,omitempty"`
+	PonSTag                    int32    `protobuf:"varint,3,opt,name=pon_s_tag,json=ponSTag,proto3" json:"pon_s_tag,omitempty"`
+	TechnologyProfileId        int32    `protobuf:"varint,4,opt,name=technology_profile_id,json=technologyProfileId,proto3" json:"technology_profile_id,omitempty"`
+	UpstreamBandwidthProfile   string   `protobuf:"bytes,5,opt,name=upstream_bandwidth_profile,json=upstreamBandwidthProfile,proto3" json:"upstream_bandwidth_profile,omitempty"`
+	DownstreamBandwidthProfile string   `protobuf:"bytes,6,opt,name=downstream_bandwidth_profile,json=downstreamBandwidthProfile,proto3" json:"downstream_bandwidth_profile,omitempty"`
+	IsDhcpRequired             bool     `protobuf:"varint,7,opt,name=is_dhcp_required,json=isDhcpRequired,proto3" json:"is_dhcp_required,omitempty"`
+	IsIgmpRequired             bool     `protobuf:"varint,8,opt,name=is_igmp_required,json=isIgmpRequired,proto3" json:"is_igmp_required,omitempty"`
+	IsPppoeRequired            bool     `protobuf:"varint,9,opt,name=is_pppoe_required,json=isPppoeRequired,proto3" json:"is_pppoe_required,omitempty"`
+	ConfiguredMacAddress       string   `protobuf:"bytes,10,opt,name=configured_mac_address,json=configuredMacAddress,proto3" json:"configured_mac_address,omitempty"`
+	EnableMacLearning          bool     `protobuf:"varint,11,opt,name=enable_mac_learning,json=enableMacLearning,proto3" json:"enable_mac_learning,omitempty"`
+	UsPonCTagPriority          uint32   `protobuf:"varint,12,opt,name=us_pon_c_tag_priority,json=usPonCTagPriority,proto3" json:"us_pon_c_tag_priority,omitempty"`
+	UsPonSTagPriority          uint32   `protobuf:"varint,13,opt,name=us_pon_s_tag_priority,json=usPonSTagPriority,proto3" json:"us_pon_s_tag_priority,omitempty"`
+	DsPonCTagPriority          uint32   `protobuf:"varint,14,opt,name=ds_pon_c_tag_priority,json=dsPonCTagPriority,proto3" json:"ds_pon_c_tag_priority,omitempty"`
+	DsPonSTagPriority          uint32   `protobuf:"varint,15,opt,name=ds_pon_s_tag_priority,json=dsPonSTagPriority,proto3" json:"ds_pon_s_tag_priority,omitempty"`
+	ServiceName                string   `protobuf:"bytes,16,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	XXX_NoUnkeyedLiteral       struct{} `json:"-"`
+	XXX_unrecognized           []byte   `json:"-"`
+	XXX_sizecache              int32    `json:"-"`
+}
+
+func (m *UniTag) Reset()         { *m = UniTag{} }
+func (m *UniTag) String() string { return proto.CompactTextString(m) }
+func (*UniTag) ProtoMessage()    {}
+func (*UniTag) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{2}
+}
+
+func (m *UniTag) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UniTag.Unmarshal(m, b)
+}
+func (m *UniTag) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UniTag.Marshal(b, m, deterministic)
+}
+func (m *UniTag) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UniTag.Merge(m, src)
+}
+func (m *UniTag) XXX_Size() int {
+	return xxx_messageInfo_UniTag.Size(m)
+}
+func (m *UniTag) XXX_DiscardUnknown() {
+	xxx_messageInfo_UniTag.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UniTag proto.InternalMessageInfo
+
+func (m *UniTag) GetUniTagMatch() int32 {
+	if m != nil {
+		return m.UniTagMatch
+	}
+	return 0
+}
+
+func (m *UniTag) GetPonCTag() int32 {
+	if m != nil {
+		return m.PonCTag
+	}
+	return 0
+}
+
+func (m *UniTag) GetPonSTag() int32 {
+	if m != nil {
+		return m.PonSTag
+	}
+	return 0
+}
+
+func (m *UniTag) GetTechnologyProfileId() int32 {
+	if m != nil {
+		return m.TechnologyProfileId
+	}
+	return 0
+}
+
+func (m *UniTag) GetUpstreamBandwidthProfile() string {
+	if m != nil {
+		return m.UpstreamBandwidthProfile
+	}
+	return ""
+}
+
+func (m *UniTag) GetDownstreamBandwidthProfile() string {
+	if m != nil {
+		return m.DownstreamBandwidthProfile
+	}
+	return ""
+}
+
+func (m *UniTag) GetIsDhcpRequired() bool {
+	if m != nil {
+		return m.IsDhcpRequired
+	}
+	return false
+}
+
+func (m *UniTag) GetIsIgmpRequired() bool {
+	if m != nil {
+		return m.IsIgmpRequired
+	}
+	return false
+}
+
+func (m *UniTag) GetIsPppoeRequired() bool {
+	if m != nil {
+		return m.IsPppoeRequired
+	}
+	return false
+}
+
+func (m *UniTag) GetConfiguredMacAddress() string {
+	if m != nil {
+		return m.ConfiguredMacAddress
+	}
+	return ""
+}
+
+func (m *UniTag) GetEnableMacLearning() bool {
+	if m != nil {
+		return m.EnableMacLearning
+	}
+	return false
+}
+
+func (m *UniTag) GetUsPonCTagPriority() uint32 {
+	if m != nil {
+		return m.UsPonCTagPriority
+	}
+	return 0
+}
+
+func (m *UniTag) GetUsPonSTagPriority() uint32 {
+	if m != nil {
+		return m.UsPonSTagPriority
+	}
+	return 0
+}
+
+func (m *UniTag) GetDsPonCTagPriority() uint32 {
+	if m != nil {
+		return m.DsPonCTagPriority
+	}
+	return 0
+}
+
+func (m *UniTag) GetDsPonSTagPriority() uint32 {
+	if m != nil {
+		return m.DsPonSTagPriority
+	}
+	return 0
+}
+
+func (m *UniTag) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+type OnuEntry struct {
+	Id                   string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	NasPortId            string    `protobuf:"bytes,2,opt,name=nas_port_id,json=nasPortId,proto3" json:"nas_port_id,omitempty"`
+	CircuitId            string    `protobuf:"bytes,3,opt,name=circuit_id,json=circuitId,proto3" json:"circuit_id,omitempty"`
+	RemoteId             string    `protobuf:"bytes,4,opt,name=remote_id,json=remoteId,proto3" json:"remote_id,omitempty"`
+	UniTagList           []*UniTag `protobuf:"bytes,5,rep,name=uni_tag_list,json=uniTagList,proto3" json:"uni_tag_list,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *OnuEntry) Reset()         { *m = OnuEntry{} }
+func (m *OnuEntry) String() string { return proto.CompactTextString(m) }
+func (*OnuEntry) ProtoMessage()    {}
+func (*OnuEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{3}
+}
+
+func (m *OnuEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OnuEntry.Unmarshal(m, b)
+}
+func (m *OnuEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OnuEntry.Marshal(b, m, deterministic)
+}
+func (m *OnuEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnuEntry.Merge(m, src)
+}
+func (m *OnuEntry) XXX_Size() int {
+	return xxx_messageInfo_OnuEntry.Size(m)
+}
+func (m *OnuEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnuEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnuEntry proto.InternalMessageInfo
+
+func (m *OnuEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *OnuEntry) GetNasPortId() string {
+	if m != nil {
+		return m.NasPortId
+	}
+	return ""
+}
+
+func (m *OnuEntry) GetCircuitId() string {
+	if m != nil {
+		return m.CircuitId
+	}
+	return ""
+}
+
+func (m *OnuEntry) GetRemoteId() string {
+	if m != nil {
+		return m.RemoteId
+	}
+	return ""
+}
+
+func (m *OnuEntry) GetUniTagList() []*UniTag {
+	if m != nil {
+		return m.UniTagList
+	}
+	return nil
+}
+
+type BandwidthProfile struct {
+	Id  string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Cbs int64  `protobuf:"varint,2,opt,name=cbs,proto3" json:"cbs,omitempty"`
+	Cir int64  `protobuf:"varint,3,opt,name=cir,proto3" json:"cir,omitempty"`
+	// MEF attributes
+	Air int64 `protobuf:"varint,4,opt,name=air,proto3" json:"air,omitempty"`
+	Ebs int64 `protobuf:"varint,5,opt,name=ebs,proto3" json:"ebs,omitempty"`
+	Eir int64 `protobuf:"varint,6,opt,name=eir,proto3" json:"eir,omitempty"`
+	// IETF attributes
+	Gir                  int64    `protobuf:"varint,7,opt,name=gir,proto3" json:"gir,omitempty"`
+	Pir                  int64    `protobuf:"varint,8,opt,name=pir,proto3" json:"pir,omitempty"`
+	Pbs                  int64    `protobuf:"varint,9,opt,name=pbs,proto3" json:"pbs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BandwidthProfile) Reset()         { *m = BandwidthProfile{} }
+func (m *BandwidthProfile) String() string { return proto.CompactTextString(m) }
+func (*BandwidthProfile) ProtoMessage()    {}
+func (*BandwidthProfile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{4}
+}
+
+func (m *BandwidthProfile) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BandwidthProfile.Unmarshal(m, b)
+}
+func (m *BandwidthProfile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BandwidthProfile.Marshal(b, m, deterministic)
+}
+func (m *BandwidthProfile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BandwidthProfile.Merge(m, src)
+}
+func (m *BandwidthProfile) XXX_Size() int {
+	return xxx_messageInfo_BandwidthProfile.Size(m)
+}
+func (m *BandwidthProfile) XXX_DiscardUnknown() {
+	xxx_messageInfo_BandwidthProfile.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BandwidthProfile proto.InternalMessageInfo
+
+func (m *BandwidthProfile) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *BandwidthProfile) GetCbs() int64 {
+	if m != nil {
+		return m.Cbs
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetCir() int64 {
+	if m != nil {
+		return m.Cir
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetAir() int64 {
+	if m != nil {
+		return m.Air
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetEbs() int64 {
+	if m != nil {
+		return m.Ebs
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetEir() int64 {
+	if m != nil {
+		return m.Eir
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetGir() int64 {
+	if m != nil {
+		return m.Gir
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetPir() int64 {
+	if m != nil {
+		return m.Pir
+	}
+	return 0
+}
+
+func (m *BandwidthProfile) GetPbs() int64 {
+	if m != nil {
+		return m.Pbs
+	}
+	return 0
+}
+
+// Subscriber is either an OLT or an ONU, as served on /subscribers/{ID}
+type Subscriber struct {
+	// Types that are valid to be assigned to Entry:
+	//	*Subscriber_Olt
+	//	*Subscriber_Onu
+	Entry                isSubscriber_Entry `protobuf_oneof:"entry"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Subscriber) Reset()         { *m = Subscriber{} }
+func (m *Subscriber) String() string { return proto.CompactTextString(m) }
+func (*Subscriber) ProtoMessage()    {}
+func (*Subscriber) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{5}
+}
+
+func (m *Subscriber) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Subscriber.Unmarshal(m, b)
+}
+func (m *Subscriber) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Subscriber.Marshal(b, m, deterministic)
+}
+func (m *Subscriber) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Subscriber.Merge(m, src)
+}
+func (m *Subscriber) XXX_Size() int {
+	return xxx_messageInfo_Subscriber.Size(m)
+}
+func (m *Subscriber) XXX_DiscardUnknown() {
+	xxx_messageInfo_Subscriber.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Subscriber proto.InternalMessageInfo
+
+type isSubscriber_Entry interface {
+	isSubscriber_Entry()
+}
+
+type Subscriber_Olt struct {
+	Olt *OltEntry `protobuf:"bytes,1,opt,name=olt,proto3,oneof"`
+}
+
+type Subscriber_Onu struct {
+	Onu *OnuEntry `protobuf:"bytes,2,opt,name=onu,proto3,oneof"`
+}
+
+func (*Subscriber_Olt) isSubscriber_Entry() {}
+
+func (*Subscriber_Onu) isSubscriber_Entry() {}
+
+func (m *Subscriber) GetEntry() isSubscriber_Entry {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+func (m *Subscriber) GetOlt() *OltEntry {
+	if x, ok := m.GetEntry().(*Subscriber_Olt); ok {
+		return x.Olt
+	}
+	return nil
+}
+
+func (m *Subscriber) GetOnu() *OnuEntry {
+	if x, ok := m.GetEntry().(*Subscriber_Onu); ok {
+		return x.Onu
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Subscriber) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Subscriber_Olt)(nil),
+		(*Subscriber_Onu)(nil),
+	}
+}
+
+type GetSubscriberRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSubscriberRequest) Reset()         { *m = GetSubscriberRequest{} }
+func (m *GetSubscriberRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSubscriberRequest) ProtoMessage()    {}
+func (*GetSubscriberRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{6}
+}
+
+func (m *GetSubscriberRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSubscriberRequest.Unmarshal(m, b)
+}
+func (m *GetSubscriberRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSubscriberRequest.Marshal(b, m, deterministic)
+}
+func (m *GetSubscriberRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSubscriberRequest.Merge(m, src)
+}
+func (m *GetSubscriberRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSubscriberRequest.Size(m)
+}
+func (m *GetSubscriberRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSubscriberRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSubscriberRequest proto.InternalMessageInfo
+
+func (m *GetSubscriberRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type GetBandwidthProfileRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBandwidthProfileRequest) Reset()         { *m = GetBandwidthProfileRequest{} }
+func (m *GetBandwidthProfileRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBandwidthProfileRequest) ProtoMessage()    {}
+func (*GetBandwidthProfileRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{7}
+}
+
+func (m *GetBandwidthProfileRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBandwidthProfileRequest.Unmarshal(m, b)
+}
+func (m *GetBandwidthProfileRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBandwidthProfileRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBandwidthProfileRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBandwidthProfileRequest.Merge(m, src)
+}
+func (m *GetBandwidthProfileRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBandwidthProfileRequest.Size(m)
+}
+func (m *GetBandwidthProfileRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBandwidthProfileRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBandwidthProfileRequest proto.InternalMessageInfo
+
+func (m *GetBandwidthProfileRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type ListRequest struct {
+	Kind Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=sadis.Kind" json:"kind,omitempty"`
+	// the OLTs and ONUs can be filtered by these fields, only the entries matching all of them are returned
+	CircuitId          string `protobuf:"bytes,2,opt,name=circuit_id,json=circuitId,proto3" json:"circuit_id,omitempty"`
+	RemoteId           string `protobuf:"bytes,3,opt,name=remote_id,json=remoteId,proto3" json:"remote_id,omitempty"`
+	NasPortId          string `protobuf:"bytes,4,opt,name=nas_port_id,json=nasPortId,proto3" json:"nas_port_id,omitempty"`
+	HardwareIdentifier string `protobuf:"bytes,5,opt,name=hardware_identifier,json=hardwareIdentifier,proto3" json:"hardware_identifier,omitempty"`
+	IpAddress          string `protobuf:"bytes,6,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	NasId              string `protobuf:"bytes,7,opt,name=nas_id,json=nasId,proto3" json:"nas_id,omitempty"`
+	// olt_id returns only the ONUs attached to an OLT
+	OltId string `protobuf:"bytes,8,opt,name=olt_id,json=oltId,proto3" json:"olt_id,omitempty"`
+	// limit is the maximum number of entries returned (default 100, max 1000),
+	// if there are more the next page is requested with continue_token
+	Limit                int32    `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"`
+	ContinueToken        string   `protobuf:"bytes,10,opt,name=continue_token,json=continueToken,proto3" json:"continue_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{8}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_OLT
+}
+
+func (m *ListRequest) GetCircuitId() string {
+	if m != nil {
+		return m.CircuitId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetRemoteId() string {
+	if m != nil {
+		return m.RemoteId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetNasPortId() string {
+	if m != nil {
+		return m.NasPortId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetHardwareIdentifier() string {
+	if m != nil {
+		return m.HardwareIdentifier
+	}
+	return ""
+}
+
+func (m *ListRequest) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *ListRequest) GetNasId() string {
+	if m != nil {
+		return m.NasId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetOltId() string {
+	if m != nil {
+		return m.OltId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListRequest) GetContinueToken() string {
+	if m != nil {
+		return m.ContinueToken
+	}
+	return ""
+}
+
+type ListResponse struct {
+	// total is the number of entries matching the request, across all the pages
+	Total int32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// continue_token is empty on the last page
+	ContinueToken string `protobuf:"bytes,2,opt,name=continue_token,json=continueToken,proto3" json:"continue_token,omitempty"`
+	// only the list for the requested kind is set
+	Olts                 []*OltEntry         `protobuf:"bytes,3,rep,name=olts,proto3" json:"olts,omitempty"`
+	Onus                 []*OnuEntry         `protobuf:"bytes,4,rep,name=onus,proto3" json:"onus,omitempty"`
+	BandwidthProfiles    []*BandwidthProfile `protobuf:"bytes,5,rep,name=bandwidth_profiles,json=bandwidthProfiles,proto3" json:"bandwidth_profiles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ListResponse) Reset()         { *m = ListResponse{} }
+func (m *ListResponse) String() string { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()    {}
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{9}
+}
+
+func (m *ListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+}
+func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+}
+func (m *ListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResponse.Merge(m, src)
+}
+func (m *ListResponse) XXX_Size() int {
+	return xxx_messageInfo_ListResponse.Size(m)
+}
+func (m *ListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+
+func (m *ListResponse) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *ListResponse) GetContinueToken() string {
+	if m != nil {
+		return m.ContinueToken
+	}
+	return ""
+}
+
+func (m *ListResponse) GetOlts() []*OltEntry {
+	if m != nil {
+		return m.Olts
+	}
+	return nil
+}
+
+func (m *ListResponse) GetOnus() []*OnuEntry {
+	if m != nil {
+		return m.Onus
+	}
+	return nil
+}
+
+func (m *ListResponse) GetBandwidthProfiles() []*BandwidthProfile {
+	if m != nil {
+		return m.BandwidthProfiles
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	// since resumes the changes after this revision, if 0 only the new changes are sent
+	Since                uint64   `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{10}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+// Event is a change to a served entry, for deletions the entry and owner are the last served ones
+type Event struct {
+	Revision uint64     `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	Type     Event_Type `protobuf:"varint,2,opt,name=type,proto3,enum=sadis.Event_Type" json:"type,omitempty"`
+	Kind     Kind       `protobuf:"varint,3,opt,name=kind,proto3,enum=sadis.Kind" json:"kind,omitempty"`
+	Id       string     `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`
+	Owner    *Owner     `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Types that are valid to be assigned to Entry:
+	//	*Event_Olt
+	//	*Event_Onu
+	//	*Event_BandwidthProfile
+	Entry                isEvent_Entry `protobuf_oneof:"entry"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eaf50d9374b51ec4, []int{11}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetRevision() uint64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *Event) GetType() Event_Type {
+	if m != nil {
+		return m.Type
+	}
+	return Event_ADD
+}
+
+func (m *Event) GetKind() Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Kind_OLT
+}
+
+func (m *Event) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Event) GetOwner() *Owner {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+type isEvent_Entry interface {
+	isEvent_Entry()
+}
+
+type Event_Olt struct {
+	Olt *OltEntry `protobuf:"bytes,6,opt,name=olt,proto3,oneof"`
+}
+
+type Event_Onu struct {
+	Onu *OnuEntry `protobuf:"bytes,7,opt,name=onu,proto3,oneof"`
+}
+
+type Event_BandwidthProfile struct {
+	BandwidthProfile *BandwidthProfile `protobuf:"bytes,8,opt,name=bandwidth_profile,json=bandwidthProfile,proto3,oneof"`
+}
+
+func (*Event_Olt) isEvent_Entry() {}
+
+func (*Event_Onu) isEvent_Entry() {}
+
+func (*Event_BandwidthProfile) isEvent_Entry() {}
+
+func (m *Event) GetEntry() isEvent_Entry {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+func (m *Event) GetOlt() *OltEntry {
+	if x, ok := m.GetEntry().(*Event_Olt); ok {
+		return x.Olt
+	}
+	return nil
+}
+
+func (m *Event) GetOnu() *OnuEntry {
+	if x, ok := m.GetEntry().(*Event_Onu); ok {
+		return x.Onu
+	}
+	return nil
+}
+
+func (m *Event) GetBandwidthProfile() *BandwidthProfile {
+	if x, ok := m.GetEntry().(*Event_BandwidthProfile); ok {
+		return x.BandwidthProfile
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Event) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Event_Olt)(nil),
+		(*Event_Onu)(nil),
+		(*Event_BandwidthProfile)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterEnum("sadis.Kind", Kind_name, Kind_value)
+	proto.RegisterEnum("sadis.Event_Type", Event_Type_name, Event_Type_value)
+	proto.RegisterType((*Owner)(nil), "sadis.Owner")
+	proto.RegisterType((*OltEntry)(nil), "sadis.OltEntry")
+	proto.RegisterType((*UniTag)(nil), "sadis.UniTag")
+	proto.RegisterType((*OnuEntry)(nil), "sadis.OnuEntry")
+	proto.RegisterType((*BandwidthProfile)(nil), "sadis.BandwidthProfile")
+	proto.RegisterType((*Subscriber)(nil), "sadis.Subscriber")
+	proto.RegisterType((*GetSubscriberRequest)(nil), "sadis.GetSubscriberRequest")
+	proto.RegisterType((*GetBandwidthProfileRequest)(nil), "sadis.GetBandwidthProfileRequest")
+	proto.RegisterType((*ListRequest)(nil), "sadis.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "sadis.ListResponse")
+	proto.RegisterType((*WatchRequest)(nil), "sadis.WatchRequest")
+	proto.RegisterType((*Event)(nil), "sadis.Event")
+}
+
+func init() { proto.RegisterFile("sadis.proto", fileDescriptor_eaf50d9374b51ec4) }
+
+var fileDescriptor_eaf50d9374b51ec4 = []byte{
+	// 1275 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xcd, 0x72, 0xdb, 0x36,
+	0x17, 0xb5, 0x44, 0x51, 0xb6, 0xae, 0x64, 0x45, 0x82, 0xed, 0x7c, 0x1c, 0x25, 0x5f, 0xe3, 0x30,
+	0x4d, 0xeb, 0x66, 0x12, 0x2b, 0xa3, 0x76, 0xd9, 0x99, 0xd6, 0xae, 0x94, 0x44, 0x53, 0x27, 0x56,
+	0x69, 0xa5, 0x99, 0xe9, 0x86, 0xc3, 0x1f, 0x44, 0xc2, 0x84, 0x02, 0x58, 0x00, 0xb4, 0xc7, 0x4f,
+	0xd4, 0x7d, 0xdf, 0xa1, 0xcb, 0x3e, 0x42, 0x57, 0x5d, 0xf5, 0x15, 0xba, 0xea, 0x00, 0x04, 0x25,
+	0x59, 0x96, 0x32, 0xd9, 0x11, 0xe7, 0x9c, 0x4b, 0x5c, 0x5c, 0x9e, 0x7b, 0x41, 0xa8, 0x8b, 0x20,
+	0x26, 0xe2, 0x38, 0xe5, 0x4c, 0x32, 0x64, 0xeb, 0x85, 0x1b, 0x81, 0x7d, 0x7e, 0x45, 0x31, 0x47,
+	0x77, 0xa1, 0x2a, 0x58, 0xc6, 0x23, 0xec, 0x94, 0x0e, 0x4b, 0x47, 0x35, 0xcf, 0xac, 0xd0, 0x7d,
+	0xa8, 0xd1, 0x60, 0x86, 0x45, 0x1a, 0x44, 0xd8, 0x29, 0x6b, 0x6a, 0x01, 0x20, 0x04, 0x15, 0xb5,
+	0x70, 0x2c, 0x4d, 0xe8, 0x67, 0xd4, 0x02, 0x2b, 0x23, 0xb1, 0x53, 0xd1, 0x90, 0x7a, 0x74, 0xff,
+	0x2c, 0xc1, 0xce, 0x79, 0x22, 0x07, 0x54, 0xf2, 0x6b, 0xd4, 0x84, 0x32, 0x89, 0xcd, 0x26, 0x65,
+	0x12, 0xa3, 0x2e, 0xec, 0x4d, 0x03, 0x1e, 0x5f, 0x05, 0x1c, 0xfb, 0x24, 0xc6, 0x54, 0x92, 0xf7,
+	0x04, 0x73, 0xb3, 0x15, 0x2a, 0xa8, 0xe1, 0x9c, 0x41, 0xff, 0x07, 0x20, 0xa9, 0x1f, 0xc4, 0x31,
+	0xc7, 0x42, 0x98, 0x9d, 0x6b, 0x24, 0x3d, 0xc9, 0x01, 0x74, 0x00, 0x55, 0x1a, 0x08, 0x7f, 0x9e,
+	0x81, 0x4d, 0x03, 0x31, 0x8c, 0xd1, 0x03, 0xa8, 0x67, 0x69, 0x42, 0xe8, 0x07, 0x3f, 0x65, 0x5c,
+	0x3a, 0xf6, 0x61, 0xe9, 0xc8, 0xf6, 0x20, 0x87, 0x46, 0x8c, 0x4b, 0xf4, 0x15, 0xb4, 0x29, 0x25,
+	0x7e, 0x3c, 0x8d, 0x52, 0x5f, 0xf2, 0x20, 0xf5, 0x2f, 0x49, 0xec, 0x54, 0xb5, 0xac, 0x49, 0x29,
+	0xe9, 0x4f, 0xa3, 0x74, 0xcc, 0x83, 0xf4, 0x67, 0x12, 0xbb, 0xff, 0xd8, 0x50, 0x7d, 0x4b, 0xc9,
+	0x38, 0x98, 0x20, 0x17, 0x76, 0x33, 0x4a, 0x7c, 0x19, 0x4c, 0xfc, 0x59, 0x20, 0xa3, 0xa9, 0x3e,
+	0x98, 0xed, 0xd5, 0x33, 0x4d, 0xbf, 0x56, 0x10, 0xea, 0x40, 0x2d, 0x65, 0xd4, 0x8f, 0x94, 0x4a,
+	0x9f, 0xcb, 0xf6, 0xb6, 0x53, 0x46, 0x7f, 0x50, 0xf1, 0x86, 0x13, 0x9a, 0xb3, 0xe6, 0xdc, 0x85,
+	0xe2, 0x7a, 0x70, 0x20, 0x71, 0x34, 0xa5, 0x2c, 0x61, 0x93, 0x6b, 0x3f, 0xe5, 0xec, 0x3d, 0x49,
+	0x70, 0x71, 0x30, 0xdb, 0xdb, 0x5b, 0x90, 0xa3, 0x9c, 0x1b, 0xc6, 0xe8, 0x5b, 0xe8, 0x64, 0xa9,
+	0x90, 0x1c, 0x07, 0x33, 0x3f, 0x0c, 0x68, 0x7c, 0x45, 0x62, 0x39, 0x2d, 0x62, 0xf5, 0xa9, 0x6b,
+	0x9e, 0x53, 0x28, 0x4e, 0x0b, 0x81, 0x89, 0x47, 0xdf, 0xc3, 0xfd, 0x98, 0x5d, 0xd1, 0x8d, 0xf1,
+	0x55, 0x1d, 0xdf, 0x59, 0x68, 0x6e, 0xbd, 0xe1, 0x08, 0x5a, 0x44, 0xe4, 0x45, 0xe4, 0xf8, 0xd7,
+	0x8c, 0x70, 0x1c, 0x3b, 0xdb, 0x87, 0xa5, 0xa3, 0x1d, 0xaf, 0x49, 0x84, 0xaa, 0xa1, 0x67, 0x50,
+	0xa3, 0x24, 0x93, 0xd9, 0x92, 0x72, 0xa7, 0x50, 0x0e, 0x27, 0xb3, 0x85, 0xf2, 0x09, 0xb4, 0x89,
+	0xf0, 0xd3, 0x34, 0x65, 0x78, 0x21, 0xad, 0x69, 0xe9, 0x1d, 0x22, 0x46, 0x0a, 0x9f, 0x6b, 0xbf,
+	0x81, 0xbb, 0x11, 0xa3, 0xef, 0xc9, 0x24, 0xe3, 0x38, 0xf6, 0x67, 0x41, 0x34, 0x37, 0x0a, 0xe8,
+	0xdc, 0xf7, 0x17, 0xec, 0xeb, 0x20, 0x2a, 0x3c, 0x73, 0x0c, 0x7b, 0x98, 0x06, 0x61, 0x82, 0x75,
+	0x44, 0x82, 0x03, 0x4e, 0x09, 0x9d, 0x38, 0x75, 0xbd, 0x47, 0x3b, 0xa7, 0x5e, 0x07, 0xd1, 0x99,
+	0x21, 0xd0, 0x73, 0x38, 0xc8, 0x84, 0x3f, 0xff, 0xa8, 0x7e, 0xca, 0x09, 0xe3, 0x44, 0x5e, 0x3b,
+	0x8d, 0xc3, 0xd2, 0xd1, 0xae, 0xd7, 0xce, 0xc4, 0x28, 0xff, 0xbe, 0x23, 0x43, 0x2c, 0x45, 0x88,
+	0x9b, 0x11, 0xbb, 0x4b, 0x11, 0x17, 0x2b, 0x11, 0xf1, 0xda, 0x3d, 0x9a, 0x79, 0x44, 0xbc, 0x6e,
+	0x8f, 0x78, 0xed, 0x1e, 0x77, 0x96, 0x22, 0x6e, 0xec, 0xf1, 0x10, 0x1a, 0x02, 0xf3, 0x4b, 0x12,
+	0x61, 0x5f, 0xb7, 0x71, 0x4b, 0xd7, 0xa8, 0x6e, 0xb0, 0x37, 0xc1, 0x0c, 0xbb, 0xbf, 0xa9, 0xde,
+	0xa5, 0xd9, 0xfa, 0xde, 0xfd, 0x0c, 0xea, 0xaa, 0xd7, 0x54, 0x47, 0x29, 0x5f, 0xce, 0xc7, 0x83,
+	0x50, 0x1d, 0x35, 0x8c, 0x55, 0xab, 0x46, 0x84, 0x47, 0x19, 0xd1, 0xb4, 0x69, 0x55, 0x83, 0x0c,
+	0x63, 0x74, 0x0f, 0x6a, 0x1c, 0xcf, 0x98, 0xc4, 0x8b, 0x6e, 0xdd, 0xc9, 0x81, 0xa1, 0x9a, 0x0b,
+	0x8d, 0xa2, 0xb3, 0x12, 0x22, 0x54, 0xc7, 0x5a, 0x47, 0xf5, 0xde, 0xee, 0x71, 0x3e, 0xc4, 0xf2,
+	0xf6, 0xf3, 0x20, 0xef, 0xb3, 0x33, 0x22, 0xa4, 0xfb, 0x7b, 0x09, 0x5a, 0xb7, 0xfc, 0xb8, 0x9a,
+	0x71, 0x0b, 0xac, 0x28, 0x14, 0x3a, 0x53, 0xcb, 0x53, 0x8f, 0x1a, 0x21, 0xdc, 0xb1, 0x0c, 0x42,
+	0xb8, 0x42, 0x02, 0xc2, 0x75, 0x42, 0x96, 0x67, 0x05, 0x39, 0x82, 0x43, 0xa1, 0xdb, 0xc7, 0xf2,
+	0xd4, 0xa3, 0x46, 0x08, 0x77, 0xaa, 0x06, 0xc9, 0x35, 0x13, 0xc2, 0xb5, 0xd9, 0x2d, 0xcf, 0x9a,
+	0xe4, 0x48, 0x4a, 0xb8, 0x36, 0xb5, 0xe5, 0x59, 0xa9, 0x41, 0x42, 0xe1, 0xd4, 0x0c, 0x12, 0x0a,
+	0xd7, 0x07, 0xb8, 0xc8, 0x42, 0x11, 0x71, 0x12, 0x62, 0x8e, 0x1e, 0x81, 0xc5, 0x12, 0xa9, 0xd3,
+	0xad, 0xf7, 0xee, 0x98, 0xa3, 0x16, 0x93, 0xf3, 0xd5, 0x96, 0xa7, 0x58, 0x2d, 0xa2, 0x99, 0x53,
+	0xbe, 0x29, 0xa2, 0xd9, 0x42, 0x44, 0xb3, 0xd3, 0x6d, 0xb0, 0xb1, 0x5a, 0xbb, 0x5f, 0xc0, 0xfe,
+	0x4b, 0x2c, 0x17, 0x7b, 0xa8, 0x4e, 0xc1, 0x42, 0xae, 0x16, 0xc6, 0x7d, 0x0a, 0x9d, 0x97, 0x58,
+	0xae, 0xd6, 0x6f, 0x93, 0xfa, 0x8f, 0x32, 0xd4, 0x55, 0xd1, 0x0b, 0xfe, 0x01, 0x54, 0x3e, 0x10,
+	0x9a, 0x2b, 0x9a, 0xbd, 0xba, 0x49, 0xea, 0x47, 0x42, 0x63, 0x4f, 0x13, 0x2b, 0x4e, 0x28, 0x7f,
+	0xd4, 0x09, 0xd6, 0x8a, 0x13, 0x56, 0x5c, 0x56, 0x59, 0x75, 0xd9, 0x86, 0x1b, 0xc4, 0xfe, 0xc4,
+	0x1b, 0xa4, 0xba, 0xf9, 0x06, 0xd9, 0x5e, 0xbe, 0x41, 0x0e, 0xa0, 0xca, 0x12, 0x9d, 0xc1, 0x4e,
+	0x0e, 0xb3, 0x44, 0xed, 0xbe, 0x0f, 0x76, 0x42, 0x66, 0x44, 0xea, 0xaf, 0x6a, 0x7b, 0xf9, 0x02,
+	0x3d, 0x86, 0x66, 0xc4, 0xa8, 0x24, 0x34, 0xc3, 0xbe, 0x64, 0x1f, 0x30, 0x35, 0xf3, 0x67, 0xb7,
+	0x40, 0xc7, 0x0a, 0x74, 0xff, 0x2a, 0x41, 0x23, 0xaf, 0xa3, 0x48, 0x19, 0x15, 0x58, 0xbd, 0x4d,
+	0x32, 0x19, 0x24, 0xe6, 0x1e, 0xc9, 0x17, 0x6b, 0xde, 0x56, 0x5e, 0xf3, 0x36, 0xf4, 0x08, 0x2a,
+	0x2c, 0x91, 0xea, 0x4e, 0xb4, 0xd6, 0xf8, 0xc7, 0xd3, 0xa4, 0x16, 0xd1, 0x4c, 0x38, 0x95, 0x43,
+	0x6b, 0x8d, 0x7f, 0x3c, 0x4d, 0xa2, 0x17, 0x80, 0x6e, 0x4d, 0x7f, 0x61, 0x5a, 0xf0, 0x7f, 0x26,
+	0xe4, 0x96, 0x57, 0xda, 0xe1, 0x0a, 0x22, 0xdc, 0xcf, 0xa1, 0xf1, 0x4e, 0xdd, 0x81, 0x85, 0x4f,
+	0xf6, 0xc1, 0x16, 0x84, 0x9a, 0x9f, 0x8c, 0x8a, 0x97, 0x2f, 0xdc, 0xbf, 0xcb, 0x60, 0x0f, 0x2e,
+	0x31, 0x95, 0xa8, 0x03, 0x3b, 0x1c, 0x5f, 0x12, 0x41, 0x18, 0x35, 0x92, 0xf9, 0x1a, 0x3d, 0x86,
+	0x8a, 0xbc, 0x4e, 0xf3, 0x9f, 0x90, 0x66, 0xaf, 0x6d, 0xb2, 0xd0, 0x71, 0xc7, 0xe3, 0xeb, 0x14,
+	0x7b, 0x9a, 0x9e, 0x5b, 0xd1, 0xda, 0x64, 0xc5, 0xdc, 0xcb, 0x95, 0xf9, 0x48, 0x70, 0xc1, 0x66,
+	0x57, 0xd4, 0x18, 0xa6, 0xde, 0x6b, 0x14, 0x15, 0x51, 0x98, 0x97, 0x53, 0x45, 0x63, 0x56, 0x3f,
+	0xa5, 0x31, 0xb7, 0x3f, 0xd6, 0x98, 0xe8, 0x05, 0xb4, 0x6f, 0xdf, 0xab, 0x3b, 0x87, 0xa5, 0x8f,
+	0x14, 0xf6, 0xd5, 0x96, 0xd7, 0x5a, 0x2d, 0xad, 0xfb, 0x25, 0x54, 0xd4, 0xa1, 0xd1, 0x36, 0x58,
+	0x27, 0xfd, 0x7e, 0x6b, 0x0b, 0x01, 0x54, 0xdf, 0x8e, 0xfa, 0x27, 0xe3, 0x41, 0xab, 0xa4, 0x9e,
+	0xfb, 0x83, 0xb3, 0xc1, 0x78, 0xd0, 0x2a, 0xcf, 0x27, 0xc1, 0x93, 0x2e, 0x54, 0x54, 0x15, 0x54,
+	0xc4, 0xf9, 0xd9, 0xb8, 0xb5, 0xa5, 0x1f, 0xde, 0xbc, 0x6d, 0x95, 0xd0, 0x01, 0xb4, 0x4f, 0x4f,
+	0xde, 0xf4, 0xdf, 0x0d, 0xfb, 0xe3, 0x57, 0xfe, 0xc8, 0x3b, 0x7f, 0x31, 0x3c, 0x1b, 0xb4, 0xca,
+	0xbd, 0x7f, 0x4b, 0x60, 0x5f, 0xa8, 0x8c, 0xd0, 0x77, 0xb0, 0x7b, 0x63, 0x88, 0xa0, 0x7b, 0x26,
+	0xd5, 0x75, 0xa3, 0xa5, 0x53, 0x7c, 0x9a, 0x25, 0xfd, 0x4f, 0xb0, 0xb7, 0x66, 0xba, 0xa0, 0x87,
+	0x8b, 0xd7, 0x6c, 0x98, 0x3c, 0x9d, 0x4d, 0x45, 0x41, 0x5d, 0xa8, 0xa8, 0xce, 0x41, 0xc8, 0x08,
+	0x96, 0xc6, 0x51, 0x67, 0xef, 0x06, 0x66, 0x5a, 0xeb, 0x29, 0xd8, 0xda, 0x8b, 0xa8, 0x60, 0x97,
+	0x9d, 0xd9, 0x69, 0x2c, 0xfb, 0xe9, 0x79, 0xe9, 0xb4, 0xf7, 0xcb, 0xf3, 0x09, 0x91, 0xd3, 0x2c,
+	0x3c, 0x8e, 0xd8, 0xac, 0xcb, 0x52, 0x4c, 0x23, 0xc6, 0xe3, 0x6e, 0x18, 0x0a, 0x32, 0x7b, 0xa6,
+	0xa5, 0xcf, 0xd4, 0x35, 0x89, 0x79, 0x37, 0x48, 0x49, 0x57, 0x03, 0x61, 0x55, 0xff, 0x5a, 0x7f,
+	0xfd, 0xdf, 0x00, 0x11, 0xc1, 0xee, 0x23, 0x69, 0x0b, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// SadisClient is the client API for Sadis service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SadisClient interface {
+	GetSubscriber(ctx context.Context, in *GetSubscriberRequest, opts ...grpc.CallOption) (*Subscriber, error)
+	GetBandwidthProfile(ctx context.Context, in *GetBandwidthProfileRequest, opts ...grpc.CallOption) (*BandwidthProfile, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Watch streams the changes to the served entries
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Sadis_WatchClient, error)
+}
+
+type sadisClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSadisClient(cc *grpc.ClientConn) SadisClient {
+	return &sadisClient{cc}
+}
+
+func (c *sadisClient) GetSubscriber(ctx context.Context, in *GetSubscriberRequest, opts ...grpc.CallOption) (*Subscriber, error) {
+	out := new(Subscriber)
+	err := c.cc.Invoke(ctx, "/sadis.Sadis/GetSubscriber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sadisClient) GetBandwidthProfile(ctx context.Context, in *GetBandwidthProfileRequest, opts ...grpc.CallOption) (*BandwidthProfile, error) {
+	out := new(BandwidthProfile)
+	err := c.cc.Invoke(ctx, "/sadis.Sadis/GetBandwidthProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sadisClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/sadis.Sadis/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sadisClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Sadis_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Sadis_serviceDesc.Streams[0], "/sadis.Sadis/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sadisWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sadis_WatchClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type sadisWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *sadisWatchClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SadisServer is the server API for Sadis service.
+type SadisServer interface {
+	GetSubscriber(context.Context, *GetSubscriberRequest) (*Subscriber, error)
+	GetBandwidthProfile(context.Context, *GetBandwidthProfileRequest) (*BandwidthProfile, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Watch streams the changes to the served entries
+	Watch(*WatchRequest, Sadis_WatchServer) error
+}
+
+// UnimplementedSadisServer can be embedded to have forward compatible implementations.
+type UnimplementedSadisServer struct {
+}
+
+func (*UnimplementedSadisServer) GetSubscriber(ctx context.Context, req *GetSubscriberRequest) (*Subscriber, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubscriber not implemented")
+}
+func (*UnimplementedSadisServer) GetBandwidthProfile(ctx context.Context, req *GetBandwidthProfileRequest) (*BandwidthProfile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBandwidthProfile not implemented")
+}
+func (*UnimplementedSadisServer) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (*UnimplementedSadisServer) Watch(req *WatchRequest, srv Sadis_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+func RegisterSadisServer(s *grpc.Server, srv SadisServer) {
+	s.RegisterService(&_Sadis_serviceDesc, srv)
+}
+
+func _Sadis_GetSubscriber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubscriberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SadisServer).GetSubscriber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sadis.Sadis/GetSubscriber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SadisServer).GetSubscriber(ctx, req.(*GetSubscriberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sadis_GetBandwidthProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBandwidthProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SadisServer).GetBandwidthProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sadis.Sadis/GetBandwidthProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SadisServer).GetBandwidthProfile(ctx, req.(*GetBandwidthProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sadis_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SadisServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sadis.Sadis/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SadisServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sadis_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SadisServer).Watch(m, &sadisWatchServer{stream})
+}
+
+type Sadis_WatchServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type sadisWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *sadisWatchServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Sadis_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "sadis.Sadis",
+	HandlerType: (*SadisServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSubscriber",
+			Handler:    _Sadis_GetSubscriber_Handler,
+		},
+		{
+			MethodName: "GetBandwidthProfile",
+			Handler:    _Sadis_GetBandwidthProfile_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Sadis_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Sadis_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sadis.proto",
+}
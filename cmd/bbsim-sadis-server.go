@@ -24,7 +24,10 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 )
 
 var (
@@ -71,9 +74,107 @@ func init() {
 }
 
 func main() {
-	ctx := context.Background()
+	// the root context is cancelled on SIGTERM (eg: when Kubernetes stops the pod) or SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if cf.SnapshotExport != "" || cf.SnapshotImport != "" {
+		if err := runSnapshotCommand(ctx); err != nil {
+			logger.Fatalw(ctx, "snapshot-command-failed", log.Fields{"server": cf.ServerURL, "err": err})
+		}
+		return
+	}
+
 	logger.Info(ctx, "bbsim-sadis-server-started")
 
+	if !isValidConflictPolicy(cf.ConflictPolicy) {
+		logger.Fatalw(ctx, "invalid-conflict-policy", log.Fields{"policy": cf.ConflictPolicy, "allowed": core.ConflictPolicies})
+	}
+
+	mem := core.NewStore()
+	mem.SetConflictPolicy(cf.ConflictPolicy, cf.NamespacePriority)
+
+	validator, err := core.NewValidator(cf.ValidationRules)
+	if err != nil {
+		logger.Fatalw(ctx, "invalid-validation-rules", log.Fields{"rules": cf.ValidationRules, "err": err})
+	}
+	mem.SetValidator(validator)
+
+	var store core.Storage = mem
+	if cf.StoreBackend == utils.StoreBolt {
+		boltStore, err := core.NewBoltStore(ctx, cf.StorePath, mem)
+		if err != nil {
+			logger.Fatalw(ctx, "cannot-open-store", log.Fields{"path": cf.StorePath, "err": err})
+		}
+		store = boltStore
+	}
+
+	fetcher := core.NewFetcher(store, cf)
+	fetcher.Start(ctx)
+
+	// the Kubernetes client is only needed to discover the pods and to review tokens
+	var client kubernetes.Interface
+	if cf.DiscoveryMode == utils.DiscoveryKubernetes || cf.AuthTokenReview {
+		client = newKubernetesClient()
+	}
+
+	var discovery core.Discovery
+	if cf.DiscoveryMode == utils.DiscoveryStatic {
+		logger.Infow(ctx, "using-static-discovery", log.Fields{"endpoints": cf.StaticEndpoints, "file": cf.StaticEndpointsFile})
+		discovery = core.NewStaticWatcher(store, fetcher, cf)
+	} else {
+		discovery = core.NewWatcher(client, store, fetcher, cf)
+	}
+
+	auth, err := core.NewAuth(cf, client)
+	if err != nil {
+		logger.Fatalw(ctx, "cannot-setup-authentication", log.Fields{"err": err})
+	}
+
+	health := core.NewHealth(discovery, fetcher, cf)
+	server := core.NewServer(store, health, auth, cf)
+
+	wg := sync.WaitGroup{}
+
+	wg.Add(2)
+
+	go health.Run("discovery", func() { discovery.Watch(ctx, &wg) })
+	go health.Run("http", func() { server.StartSadisServer(ctx, &wg) })
+
+	if cf.GrpcPort != 0 {
+		wg.Add(1)
+		grpcServer := core.NewGrpcServer(store, auth, cf)
+		go health.Run("grpc", func() { grpcServer.StartGrpcServer(ctx, &wg) })
+	}
+
+	if cf.SadisDir != "" {
+		wg.Add(1)
+		fileWatcher := core.NewFileWatcher(store, cf)
+		go health.Run("files", func() { fileWatcher.Watch(ctx, &wg) })
+	}
+
+	if len(cf.KafkaAddresses) > 0 {
+		wg.Add(1)
+		publisher := core.NewKafkaPublisher(store, cf)
+		go health.Run("kafka", func() { publisher.Publish(ctx, &wg) })
+	}
+
+	<-ctx.Done()
+	// restore the default handling, so that a second signal forces the exit while we're draining
+	stop()
+	logger.Info(ctx, "bbsim-sadis-server-stopping")
+
+	// wait for the in-flight requests to be served and for everything that writes to the store to stop
+	wg.Wait()
+	fetcher.Wait()
+
+	if err := store.Close(); err != nil {
+		logger.Errorw(ctx, "cannot-close-store", log.Fields{"err": err})
+	}
+	logger.Info(ctx, "bbsim-sadis-server-stopped")
+}
+
+func newKubernetesClient() *kubernetes.Clientset {
 	var config *rest.Config
 	var err error
 
@@ -91,18 +192,41 @@ func main() {
 	if err != nil {
 		panic(err.Error())
 	}
+	return clientset
+}
 
-	store := core.NewStore()
-
-	watcher := core.NewWatcher(clientset, store, cf)
-	server := core.NewServer(store)
-
-	wg := sync.WaitGroup{}
-
-	wg.Add(2)
+// runSnapshotCommand exports or imports a snapshot of the store of a running server
+func runSnapshotCommand(ctx context.Context) error {
+	if cf.SnapshotExport != "" {
+		out := os.Stdout
+		if cf.SnapshotExport != "-" {
+			file, err := os.Create(cf.SnapshotExport)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+		return core.ExportSnapshot(ctx, cf.ServerURL, cf.ServerToken, out)
+	}
 
-	go watcher.Watch(ctx, &wg)
-	go server.StartSadisServer(&wg)
+	in := os.Stdin
+	if cf.SnapshotImport != "-" {
+		file, err := os.Open(cf.SnapshotImport)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	return core.ImportSnapshot(ctx, cf.ServerURL, cf.ServerToken, in, cf.SnapshotMode)
+}
 
-	wg.Wait()
+func isValidConflictPolicy(policy string) bool {
+	for _, p := range core.ConflictPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
 }
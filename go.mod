@@ -9,9 +9,14 @@ replace (
 )
 
 require (
+	github.com/Shopify/sarama v1.29.1
+	github.com/golang/protobuf v1.5.2
 	github.com/gorilla/mux v1.8.0
 	github.com/imdario/mergo v0.3.11 // indirect
 	github.com/opencord/voltha-lib-go/v7 v7.6.3
+	github.com/prometheus/client_golang v1.11.0
+	go.etcd.io/bbolt v1.3.4
+	google.golang.org/grpc v1.44.0 // selected as required by voltha-lib-go/v7, v1.25.1 is built (see replace)
 	gotest.tools v2.2.0+incompatible
 	k8s.io/api v0.19.0
 	k8s.io/apimachinery v0.19.0
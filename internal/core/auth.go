@@ -0,0 +1,293 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// roles that can be granted to the clients, each role includes the ones before it
+const (
+	RoleNone  = "none"
+	RoleRead  = "read"
+	RoleAdmin = "admin"
+)
+
+// how long the outcome of a TokenReview is cached
+const (
+	tokenReviewTTL        = time.Minute
+	tokenReviewFailureTTL = 10 * time.Second
+	tokenReviewTimeout    = 5 * time.Second
+)
+
+// users are prefixed with the authenticator that recognized them
+const (
+	anonymousUser         = "anonymous"
+	staticTokenUserPrefix = "token:"
+	basicAuthUserPrefix   = "basic:"
+	tokenReviewUserPrefix = "k8s:"
+)
+
+func roleRank(role string) int {
+	switch role {
+	case RoleAdmin:
+		return 2
+	case RoleRead:
+		return 1
+	}
+	return 0
+}
+
+// Authenticator checks the credentials in a request
+type Authenticator interface {
+	// Authenticate returns the user and the role granted to the credentials in the request,
+	// ok is false if the credentials are not known to this authenticator
+	Authenticate(ctx context.Context, r *http.Request) (user string, role string, ok bool)
+}
+
+// Auth grants a role to every request, requests without credentials get the anonymous role
+type Auth struct {
+	authenticators []Authenticator
+	anonymousRole  string
+}
+
+// NewAuth creates the authenticators enabled in the configuration,
+// client is only used if the Kubernetes TokenReview is enabled
+func NewAuth(cf *utils.ConfigFlags, client kubernetes.Interface) (*Auth, error) {
+	auth := &Auth{anonymousRole: cf.AuthAnonymousRole}
+
+	tokens := map[string]string{}
+	if cf.AdminToken != "" {
+		tokens[cf.AdminToken] = RoleAdmin
+	}
+	if cf.AuthTokensFile != "" {
+		if err := readCredentials(cf.AuthTokensFile, 2, func(fields []string) {
+			tokens[fields[0]] = fields[1]
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if len(tokens) > 0 {
+		auth.authenticators = append(auth.authenticators, staticTokens(tokens))
+	}
+
+	if cf.AuthBasicFile != "" {
+		users := basicUsers{}
+		if err := readCredentials(cf.AuthBasicFile, 3, func(fields []string) {
+			users[fields[0]] = basicUser{password: fields[1], role: fields[2]}
+		}); err != nil {
+			return nil, err
+		}
+		auth.authenticators = append(auth.authenticators, users)
+	}
+
+	if cf.AuthTokenReview {
+		auth.authenticators = append(auth.authenticators, &tokenReview{
+			client:      client,
+			adminGroups: cf.AuthTokenReviewAdminGroups,
+			cache:       utilcache.NewExpiring(),
+		})
+	}
+
+	return auth, nil
+}
+
+// readCredentials reads a file with one entry per line and the fields separated by colons,
+// the last field is always the role. Empty lines and lines starting with # are ignored.
+func readCredentials(file string, fields int, add func(fields []string)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		// the role is the last field, so secrets can contain colons
+		parts := strings.Split(text, ":")
+		if len(parts) < fields {
+			return fmt.Errorf("%s:%d: expected %d fields separated by colons", file, line, fields)
+		}
+		role := parts[len(parts)-1]
+		if role != RoleRead && role != RoleAdmin {
+			return fmt.Errorf("%s:%d: invalid role %s, allowed values are: %s, %s", file, line, role, RoleRead, RoleAdmin)
+		}
+		values := append([]string{}, parts[:fields-2]...)
+		values = append(values, strings.Join(parts[fields-2:len(parts)-1], ":"), role)
+		add(values)
+	}
+	return scanner.Err()
+}
+
+// authenticate returns the user and role for the request
+func (a *Auth) authenticate(ctx context.Context, r *http.Request) (string, string, bool) {
+	if r.Header.Get("Authorization") == "" {
+		return anonymousUser, a.anonymousRole, true
+	}
+	for _, authenticator := range a.authenticators {
+		if user, role, ok := authenticator.Authenticate(ctx, r); ok {
+			return user, role, true
+		}
+	}
+	return "", RoleNone, false
+}
+
+// authorize only lets the requests from clients with at least the required role through
+func (s Server) authorize(required string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if required == RoleAdmin && len(s.auth.authenticators) == 0 {
+			s.writeError(ctx, w, http.StatusForbidden, "The admin API is disabled, set -admin_token or configure authentication to enable it.")
+			return
+		}
+
+		user, role, ok := s.auth.authenticate(ctx, r)
+		if !ok || (user == anonymousUser && roleRank(role) < roleRank(required)) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="bbsim-sadis-server"`)
+			w.Header().Add("WWW-Authenticate", "Bearer")
+			s.writeError(ctx, w, http.StatusUnauthorized, "Valid credentials are required.")
+			return
+		}
+		if roleRank(role) < roleRank(required) {
+			s.writeError(ctx, w, http.StatusForbidden, fmt.Sprintf("User %s is not allowed to access this resource.", user))
+			return
+		}
+
+		logger.Debugw(ctx, "authorized-request", log.Fields{"user": user, "role": role, "path": r.URL.Path})
+		next(w, r)
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// staticTokens maps bearer tokens to roles
+type staticTokens map[string]string
+
+func (t staticTokens) Authenticate(ctx context.Context, r *http.Request) (string, string, bool) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return "", "", false
+	}
+	// compare all of them so that the time taken doesn't depend on the token
+	user, role, found := "", "", false
+	for candidate, candidateRole := range t {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			user, role, found = staticTokenUserPrefix+fmt.Sprintf("%x", sha256.Sum256([]byte(candidate)))[:8], candidateRole, true
+		}
+	}
+	return user, role, found
+}
+
+type basicUser struct {
+	password string
+	role     string
+}
+
+// basicUsers maps HTTP basic authentication usernames to their password and role
+type basicUsers map[string]basicUser
+
+func (u basicUsers) Authenticate(ctx context.Context, r *http.Request) (string, string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+	user, ok := u[username]
+	if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(user.password)) != 1 {
+		return "", "", false
+	}
+	return basicAuthUserPrefix + username, user.role, true
+}
+
+// tokenReview validates bearer tokens (eg: service account tokens) with the Kubernetes API,
+// authenticated users get the read role, or the admin role if they belong to one of the admin groups
+type tokenReview struct {
+	client      kubernetes.Interface
+	adminGroups []string
+	// cache contains the outcome of the recent reviews, keyed by the token hash
+	cache *utilcache.Expiring
+}
+
+type tokenReviewResult struct {
+	user string
+	role string
+	ok   bool
+}
+
+func (t *tokenReview) Authenticate(ctx context.Context, r *http.Request) (string, string, bool) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return "", "", false
+	}
+
+	key := sha256.Sum256([]byte(token))
+	if cached, ok := t.cache.Get(key); ok {
+		result := cached.(tokenReviewResult)
+		return result.user, result.role, result.ok
+	}
+
+	reviewCtx, cancel := context.WithTimeout(ctx, tokenReviewTimeout)
+	defer cancel()
+
+	review, err := t.client.AuthenticationV1().TokenReviews().Create(reviewCtx,
+		&authv1.TokenReview{Spec: authv1.TokenReviewSpec{Token: token}}, metav1.CreateOptions{})
+	if err != nil {
+		// don't cache the failure, the API server may be temporarily unavailable
+		logger.Errorw(ctx, "token-review-failed", log.Fields{"err": err})
+		return "", "", false
+	}
+
+	result := tokenReviewResult{}
+	ttl := tokenReviewFailureTTL
+	if review.Status.Authenticated {
+		result = tokenReviewResult{user: tokenReviewUserPrefix + review.Status.User.Username, role: RoleRead, ok: true}
+		for _, group := range review.Status.User.Groups {
+			for _, admin := range t.adminGroups {
+				if group == admin {
+					result.role = RoleAdmin
+				}
+			}
+		}
+		ttl = tokenReviewTTL
+	}
+	t.cache.Set(key, result, ttl)
+	return result.user, result.role, result.ok
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"io/ioutil"
+	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func authRequest(t *testing.T, method string, url string, setAuth func(r *http.Request)) int {
+	req, err := http.NewRequest(method, url, nil)
+	assert.NilError(t, err)
+	if setAuth != nil {
+		setAuth(req)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	return res.StatusCode
+}
+
+func basic(username string, password string) func(r *http.Request) {
+	return func(r *http.Request) { r.SetBasicAuth(username, password) }
+}
+
+func bearer(token string) func(r *http.Request) {
+	return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+}
+
+func Test_Auth(t *testing.T) {
+	dir := t.TempDir()
+
+	cf := utils.NewConfigFlags()
+	cf.AuthAnonymousRole = RoleNone
+	cf.AuthBasicFile = filepath.Join(dir, "users")
+	cf.AuthTokensFile = filepath.Join(dir, "tokens")
+	assert.NilError(t, ioutil.WriteFile(cf.AuthBasicFile, []byte("# ONOS\nonos:pass:word:read\nops:secret:admin\n"), 0600))
+	assert.NilError(t, ioutil.WriteFile(cf.AuthTokensFile, []byte("reader-token:read\n"), 0600))
+
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+
+	server := httptest.NewServer(NewServer(NewStore(), nil, auth, cf).router())
+	defer server.Close()
+
+	profile := server.URL + "/profiles/Default"
+
+	tests := []struct {
+		name    string
+		method  string
+		setAuth func(r *http.Request)
+		status  int
+	}{
+		{"anonymous-read", http.MethodGet, nil, http.StatusUnauthorized},
+		{"basic-read", http.MethodGet, basic("onos", "pass:word"), http.StatusNotFound},
+		{"basic-wrong-password", http.MethodGet, basic("onos", "pass"), http.StatusUnauthorized},
+		{"basic-read-write", http.MethodDelete, basic("onos", "pass:word"), http.StatusForbidden},
+		{"basic-admin-write", http.MethodDelete, basic("ops", "secret"), http.StatusNotFound},
+		{"token-read", http.MethodGet, bearer("reader-token"), http.StatusNotFound},
+		{"token-read-write", http.MethodDelete, bearer("reader-token"), http.StatusForbidden},
+		{"unknown-token", http.MethodGet, bearer("unknown"), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, authRequest(t, tt.method, profile, tt.setAuth), tt.status)
+		})
+	}
+
+	// probes and metrics are always accessible
+	assert.Equal(t, authRequest(t, http.MethodGet, server.URL+"/metrics", nil), http.StatusOK)
+}
+
+func Test_Auth_invalidFile(t *testing.T) {
+	cf := utils.NewConfigFlags()
+	cf.AuthTokensFile = filepath.Join(t.TempDir(), "tokens")
+	assert.NilError(t, ioutil.WriteFile(cf.AuthTokensFile, []byte("token:superuser\n"), 0600))
+
+	_, err := NewAuth(cf, nil)
+	assert.ErrorContains(t, err, "invalid role superuser")
+}
+
+func Test_Auth_TokenReview(t *testing.T) {
+	reviews := 0
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/apis/authentication.k8s.io/v1/tokenreviews")
+		reviews++
+
+		review := authv1.TokenReview{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&review))
+		switch review.Spec.Token {
+		case "sa-token":
+			review.Status = authv1.TokenReviewStatus{Authenticated: true,
+				User: authv1.UserInfo{Username: "system:serviceaccount:voltha:onos"}}
+		case "admin-token":
+			review.Status = authv1.TokenReviewStatus{Authenticated: true,
+				User: authv1.UserInfo{Username: "jane", Groups: []string{"system:authenticated", "sadis-admins"}}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	defer apiServer.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: apiServer.URL})
+	assert.NilError(t, err)
+
+	cf := utils.NewConfigFlags()
+	cf.AuthTokenReview = true
+	cf.AuthTokenReviewAdminGroups = []string{"sadis-admins"}
+	auth, err := NewAuth(cf, client)
+	assert.NilError(t, err)
+
+	server := httptest.NewServer(NewServer(NewStore(), nil, auth, cf).router())
+	defer server.Close()
+
+	profile := server.URL + "/profiles/Default"
+
+	assert.Equal(t, authRequest(t, http.MethodGet, profile, bearer("sa-token")), http.StatusNotFound)
+	assert.Equal(t, authRequest(t, http.MethodDelete, profile, bearer("sa-token")), http.StatusForbidden)
+	assert.Equal(t, authRequest(t, http.MethodDelete, profile, bearer("admin-token")), http.StatusNotFound)
+	assert.Equal(t, authRequest(t, http.MethodGet, profile, bearer("invalid")), http.StatusUnauthorized)
+	// anonymous clients can still read
+	assert.Equal(t, authRequest(t, http.MethodGet, profile, nil), http.StatusNotFound)
+
+	// the reviews are cached
+	assert.Equal(t, reviews, 3)
+	assert.Equal(t, authRequest(t, http.MethodGet, profile, bearer("sa-token")), http.StatusNotFound)
+	assert.Equal(t, reviews, 3)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+var ownersBucket = []byte("owners")
+
+// ownerRecord is what is persisted for every owner
+type ownerRecord struct {
+	Owner Owner             `json:"owner"`
+	Olts  []SadisOltEntry   `json:"olts"`
+	Onus  []SadisOnuEntryV2 `json:"onus"`
+	Bps   []SadisBWPEntry   `json:"bps"`
+	Olt   string            `json:"olt,omitempty"`
+}
+
+// BoltStore keeps the entries in memory and persists them in a bbolt database,
+// so that after a restart ONOS is served the last known state while the BBSim instances are queried again.
+// The entries are persisted per owner, as the resolution (precedence and conflicts) is recomputed when they're loaded.
+type BoltStore struct {
+	*Store
+	db *bolt.DB
+}
+
+// NewBoltStore opens (or creates) the database at path and loads the entries it contains in mem
+func NewBoltStore(ctx context.Context, path string, mem *Store) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &BoltStore{Store: mem, db: db}
+	if err := s.load(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load restores the entries persisted in the database
+func (s *BoltStore) load(ctx context.Context) error {
+	records := []ownerRecord{}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(ownersBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var record ownerRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		s.Store.replaceOwner(ctx, record.Owner, entrySet{olts: record.Olts, onus: record.Onus, bps: record.Bps, olt: record.Olt})
+	}
+	logger.Infow(ctx, "restored-persisted-entries", log.Fields{"path": s.db.Path(), "owners": len(records)})
+	return nil
+}
+
+// persist writes the entries currently published by the owner to the database,
+// failures are logged and the entries are still served from memory
+func (s *BoltStore) persist(ctx context.Context, owner Owner) {
+	set := s.Store.ownerEntries(owner)
+	key := []byte(owner.String() + "|" + owner.UID)
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ownersBucket)
+		if len(set.olts) == 0 && len(set.onus) == 0 && len(set.bps) == 0 {
+			return bucket.Delete(key)
+		}
+		value, err := json.Marshal(ownerRecord{Owner: owner, Olts: set.olts, Onus: set.onus, Bps: set.bps, Olt: set.olt})
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+	if err != nil {
+		logger.Errorw(ctx, "cannot-persist-entries", log.Fields{"owner": owner.String(), "err": err})
+	}
+}
+
+func (s *BoltStore) addOlt(ctx context.Context, entry SadisOltEntry, owner Owner) {
+	s.Store.addOlt(ctx, entry, owner)
+	s.persist(ctx, owner)
+}
+
+func (s *BoltStore) addOnu(ctx context.Context, entry SadisOnuEntryV2, owner Owner) {
+	s.Store.addOnu(ctx, entry, owner)
+	s.persist(ctx, owner)
+}
+
+func (s *BoltStore) addBp(ctx context.Context, entry SadisBWPEntry, owner Owner) {
+	s.Store.addBp(ctx, entry, owner)
+	s.persist(ctx, owner)
+}
+
+func (s *BoltStore) removeOwner(ctx context.Context, owner Owner) {
+	s.Store.removeOwner(ctx, owner)
+	s.persist(ctx, owner)
+}
+
+func (s *BoltStore) removeEntry(ctx context.Context, kind string, id string, owner Owner) bool {
+	removed := s.Store.removeEntry(ctx, kind, id, owner)
+	s.persist(ctx, owner)
+	return removed
+}
+
+func (s *BoltStore) replaceOwner(ctx context.Context, owner Owner, set entrySet) {
+	s.Store.replaceOwner(ctx, owner, set)
+	s.persist(ctx, owner)
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"path/filepath"
+	"testing"
+)
+
+func Test_BoltStore_restart(t *testing.T) {
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "store.db")
+
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	gone := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim1", UID: "uid-1"}
+
+	store, err := NewBoltStore(ctx, path, NewStore())
+	assert.NilError(t, err)
+
+	store.replaceOwner(ctx, pod, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}},
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10}}}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 1000}},
+	})
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_1"}, gone)
+	store.removeOwner(ctx, gone)
+	assert.NilError(t, store.Close())
+
+	// the entries are served right after a restart
+	store, err = NewBoltStore(ctx, path, NewStore())
+	assert.NilError(t, err)
+	defer store.Close()
+
+	olt, err := store.getOlt(ctx, "BBSM_OLT_0")
+	assert.NilError(t, err)
+	assert.Equal(t, olt.HardwareIdentifier, "00:00:00:00:00:01")
+	onu, err := store.getOnu(ctx, "BBSM_ONU_0-1")
+	assert.NilError(t, err)
+	assert.Equal(t, onu.UniTagList[0].PonCTag, 10)
+	bp, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 1000)
+
+	_, err = store.getOlt(ctx, "BBSM_OLT_1")
+	assert.Error(t, err, "olt-not-found-in-store")
+	assert.Assert(t, store.hasOwner(pod))
+	assert.Assert(t, !store.hasOwner(gone))
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipMinSize is the size below which a response is not worth compressing
+const gzipMinSize = 1024
+
+// gzipSuffix is appended to the ETag of the compressed representation of a response,
+// as it's a different sequence of bytes than the uncompressed one
+const gzipSuffix = "-gzip"
+
+// writeCacheable writes value as JSON with an ETag computed from its content and the Cache-Control
+// matching the configured TTL, replying 304 if the client already has it (If-None-Match).
+// If compress is true the response is gzipped for the clients accepting it.
+func (s Server) writeCacheable(w http.ResponseWriter, r *http.Request, value interface{}, compress bool) {
+	s.writeCacheableAs(w, r, value, nil, compress)
+}
+
+// writeCacheableAs is writeCacheable with the ETag computed from version instead of value (if not nil),
+// for the responses containing fields that change at every request, eg: a timestamp
+func (s Server) writeCacheableAs(w http.ResponseWriter, r *http.Request, value interface{}, version interface{}, compress bool) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		s.writeError(r.Context(), w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	body = append(body, '\n')
+
+	content := body
+	if version != nil {
+		if content, err = json.Marshal(version); err != nil {
+			s.writeError(r.Context(), w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	sum := sha256.Sum256(content)
+	etag := hex.EncodeToString(sum[:16])
+
+	gzipped := compress && len(body) >= gzipMinSize && acceptsGzip(r)
+	if gzipped {
+		etag += gzipSuffix
+	}
+
+	header := w.Header()
+	header.Set("ETag", `"`+etag+`"`)
+	header.Set("Cache-Control", s.cacheControl())
+	if compress {
+		header.Set("Vary", "Accept-Encoding")
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/json")
+	if !gzipped {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+		return
+	}
+
+	header.Set("Content-Encoding", "gzip")
+	w.WriteHeader(http.StatusOK)
+	writer := gzip.NewWriter(w)
+	_, _ = writer.Write(body)
+	_ = writer.Close()
+}
+
+// cacheControl lets clients cache the responses for the configured TTL, without a TTL they have to revalidate
+func (s Server) cacheControl() string {
+	if ttl := int(s.config.CacheTTL.Seconds()); ttl > 0 {
+		return fmt.Sprintf("max-age=%d", ttl)
+	}
+	return "no-cache"
+}
+
+// etagMatches returns true if the If-None-Match header contains etag,
+// the same entry is matched whether it was received compressed or not
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimSuffix(etag, gzipSuffix)
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		// the comparison is weak, as the one required for If-None-Match
+		candidate = strings.Trim(strings.TrimPrefix(candidate, "W/"), `"`)
+		if strings.TrimSuffix(candidate, gzipSuffix) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip returns true if the client accepts gzip encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(coding, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if value, err := strconv.ParseFloat(q[2:], 64); err == nil && value == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
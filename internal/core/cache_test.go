@@ -0,0 +1,189 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cacheRequest(t *testing.T, url string, headers map[string]string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NilError(t, err)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	return res
+}
+
+func Test_Cache_entry(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Name: "bbsim0"}
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}, pod)
+
+	cf := utils.NewConfigFlags()
+	server := httptest.NewServer(NewServer(store, nil, nil, cf).router())
+	defer server.Close()
+
+	url := server.URL + "/subscribers/BBSM_OLT_0"
+
+	res := cacheRequest(t, url, nil)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Cache-Control"), "no-cache")
+	etag := res.Header.Get("ETag")
+	assert.Assert(t, etag != "")
+
+	// the entry didn't change
+	res = cacheRequest(t, url, map[string]string{"If-None-Match": etag})
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusNotModified)
+	assert.Equal(t, res.Header.Get("ETag"), etag)
+
+	// the entry changed
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:02"}, pod)
+	res = cacheRequest(t, url, map[string]string{"If-None-Match": etag})
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Assert(t, res.Header.Get("ETag") != etag)
+
+	// the TTL is used as max-age
+	cf = utils.NewConfigFlags()
+	cf.CacheTTL = time.Minute
+	cached := httptest.NewServer(NewServer(store, nil, nil, cf).router())
+	defer cached.Close()
+
+	res = cacheRequest(t, cached.URL+"/subscribers/BBSM_OLT_0", nil)
+	res.Body.Close()
+	assert.Equal(t, res.Header.Get("Cache-Control"), "max-age=60")
+}
+
+func Test_Cache_gzip(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	for i := 0; i < 50; i++ {
+		store.addOnu(ctx, SadisOnuEntryV2{ID: fmt.Sprintf("BBSM0000%04d-1", i), NasPortID: fmt.Sprintf("BBSM0000%04d-1", i)},
+			Owner{Source: SourcePod, Name: "bbsim0"})
+	}
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	url := server.URL + "/onus"
+
+	// setting the header explicitly disables the transparent decompression in the client
+	res := cacheRequest(t, url, map[string]string{"Accept-Encoding": "gzip"})
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Content-Encoding"), "gzip")
+	assert.Equal(t, res.Header.Get("Vary"), "Accept-Encoding")
+	etag := res.Header.Get("ETag")
+	assert.Assert(t, strings.HasSuffix(etag, `-gzip"`))
+
+	reader, err := gzip.NewReader(res.Body)
+	assert.NilError(t, err)
+	var page Page
+	assert.NilError(t, json.NewDecoder(reader).Decode(&page))
+	assert.Equal(t, page.Total, 50)
+
+	// the uncompressed representation is matched by the same ETag
+	res = cacheRequest(t, url, map[string]string{"Accept-Encoding": "identity", "If-None-Match": etag})
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusNotModified)
+	assert.Equal(t, res.Header.Get("ETag"), strings.TrimSuffix(etag, `-gzip"`)+`"`)
+}
+
+func Test_Cache_snapshot(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Name: "bbsim0"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, pod)
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	url := server.URL + "/snapshot"
+
+	res := cacheRequest(t, url, nil)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	etag := res.Header.Get("ETag")
+	assert.Assert(t, etag != "")
+
+	// the creation time of the snapshot is not part of the ETag
+	for i := 0; i < 2; i++ {
+		res = cacheRequest(t, url, map[string]string{"If-None-Match": etag})
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusNotModified)
+		assert.Equal(t, res.Header.Get("ETag"), etag)
+	}
+
+	// the content changed
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 2000}, pod)
+	res = cacheRequest(t, url, map[string]string{"If-None-Match": etag})
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Assert(t, res.Header.Get("ETag") != etag)
+}
+
+func Test_etagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		match  bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"abc-gzip"`, true},
+		{`"def", "abc"`, true},
+		{`"def"`, false},
+		{"*", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, etagMatches(tt.header, "abc"), tt.match, tt.header)
+	}
+}
+
+func Test_acceptsGzip(t *testing.T) {
+	tests := []struct {
+		header  string
+		accepts bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"identity", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.header)
+		assert.Equal(t, acceptsGzip(req), tt.accepts, tt.header)
+	}
+}
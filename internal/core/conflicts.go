@@ -0,0 +1,203 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"reflect"
+	"sort"
+)
+
+const (
+	EntryTypeOlt = "olt"
+	EntryTypeOnu = "onu"
+	EntryTypeBp  = "bp"
+)
+
+// policies applied when different owners publish the same ID with different values
+const (
+	// ConflictLastWins serves the entry that was published last
+	ConflictLastWins = "last-wins"
+	// ConflictFirstWins serves the entry that was published first
+	ConflictFirstWins = "first-wins"
+	// ConflictRejectBoth doesn't serve the entry at all until the conflict is solved
+	ConflictRejectBoth = "reject-both"
+	// ConflictNamespacePriority serves the entry from the namespace that comes first in the configured list
+	ConflictNamespacePriority = "namespace-priority"
+)
+
+// ConflictPolicies lists all the supported policies
+var ConflictPolicies = []string{ConflictLastWins, ConflictFirstWins, ConflictRejectBoth, ConflictNamespacePriority}
+
+// Conflict describes an ID that is published with different values by more than one owner
+type Conflict struct {
+	Type   string  `json:"type"`
+	ID     string  `json:"id"`
+	Owners []Owner `json:"owners"`
+	Policy string  `json:"policy"`
+	// Served is the owner whose entry is served, nil if the entry is rejected
+	Served *Owner `json:"served"`
+
+	// logged contains the conflicting pairs that have already been logged
+	logged map[string]struct{}
+}
+
+// sortedOwners returns the owners of the candidates in a stable order
+func sortedOwners(candidates map[Owner]publication) []Owner {
+	res := []Owner{}
+	for owner := range candidates {
+		res = append(res, owner)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].String() != res[j].String() {
+			return res[i].String() < res[j].String()
+		}
+		return res[i].UID < res[j].UID
+	})
+	return res
+}
+
+// pick chooses the publication to serve among the candidates, applying the conflict policy
+// if they don't agree on the value. It must be called with the ownersLock held.
+func (s *Store) pick(ctx context.Context, kind string, id string, candidates map[Owner]publication) (publication, bool) {
+	key := kind + "/" + id
+	if len(candidates) == 0 {
+		delete(s.conflicts, key)
+		return publication{}, false
+	}
+
+	owners := sortedOwners(candidates)
+	last := owners[0]
+	conflicting := false
+	for _, owner := range owners {
+		if candidates[owner].seq > candidates[last].seq {
+			last = owner
+		}
+		if !reflect.DeepEqual(candidates[owner].value, candidates[owners[0]].value) {
+			conflicting = true
+		}
+	}
+
+	// the same entry (eg: a bandwidth profile) can be legitimately published by more owners
+	if !conflicting {
+		delete(s.conflicts, key)
+		return candidates[last], true
+	}
+
+	var winner *Owner
+	switch s.conflictPolicy {
+	case ConflictFirstWins:
+		first := owners[0]
+		for _, owner := range owners {
+			if candidates[owner].since < candidates[first].since {
+				first = owner
+			}
+		}
+		winner = &first
+	case ConflictRejectBoth:
+		winner = nil
+	case ConflictNamespacePriority:
+		best := last
+		for _, owner := range owners {
+			if s.namespaceRank(owner) < s.namespaceRank(best) ||
+				(s.namespaceRank(owner) == s.namespaceRank(best) && candidates[owner].seq > candidates[best].seq) {
+				best = owner
+			}
+		}
+		winner = &best
+	default:
+		winner = &last
+	}
+
+	s.recordConflict(ctx, kind, id, owners, candidates, winner)
+
+	if winner == nil {
+		return publication{}, false
+	}
+	return candidates[*winner], true
+}
+
+// namespaceRank returns the position of the owner namespace in the priority list,
+// namespaces that are not listed come after all the others
+func (s *Store) namespaceRank(owner Owner) int {
+	for i, ns := range s.namespacePriority {
+		if ns == owner.Namespace {
+			return i
+		}
+	}
+	return len(s.namespacePriority)
+}
+
+// recordConflict keeps track of a conflict and logs every conflicting pair the first time it's detected
+func (s *Store) recordConflict(ctx context.Context, kind string, id string, owners []Owner,
+	candidates map[Owner]publication, winner *Owner) {
+	key := kind + "/" + id
+	conflict, ok := s.conflicts[key]
+	if !ok {
+		conflict = &Conflict{Type: kind, ID: id, logged: map[string]struct{}{}}
+		s.conflicts[key] = conflict
+	}
+	conflict.Owners = owners
+	conflict.Policy = s.conflictPolicy
+	conflict.Served = winner
+
+	served := ""
+	if winner != nil {
+		served = winner.String()
+	}
+
+	for i, a := range owners {
+		for _, b := range owners[i+1:] {
+			if reflect.DeepEqual(candidates[a].value, candidates[b].value) {
+				continue
+			}
+			pair := a.String() + "|" + a.UID + "|" + b.String() + "|" + b.UID
+			if _, ok := conflict.logged[pair]; ok {
+				continue
+			}
+			conflict.logged[pair] = struct{}{}
+			logger.Warnw(ctx, "conflicting-entries", log.Fields{"type": kind, "id": id, "owner": a.String(),
+				"conflictingOwner": b.String(), "policy": s.conflictPolicy, "served": served})
+		}
+	}
+}
+
+// listConflicts returns the current conflicts sorted by type and ID
+func (s *Store) listConflicts() []Conflict {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	res := []Conflict{}
+	for _, conflict := range s.conflicts {
+		c := *conflict
+		c.Owners = append([]Owner{}, conflict.Owners...)
+		if conflict.Served != nil {
+			served := *conflict.Served
+			c.Served = &served
+		}
+		c.logged = nil
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Type != res[j].Type {
+			return res[i].Type < res[j].Type
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
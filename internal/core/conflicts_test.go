@@ -0,0 +1,83 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_ConflictPolicies(t *testing.T) {
+	first := Owner{Source: SourcePod, Namespace: "team-b", Name: "bbsim0", UID: "uid-0"}
+	second := Owner{Source: SourcePod, Namespace: "team-a", Name: "bbsim0", UID: "uid-1"}
+
+	tests := []struct {
+		policy  string
+		served  string
+		missing bool
+	}{
+		{ConflictLastWins, "00:00:00:00:00:02", false},
+		{ConflictFirstWins, "00:00:00:00:00:01", false},
+		{ConflictRejectBoth, "", true},
+		{ConflictNamespacePriority, "00:00:00:00:00:02", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ctx := context.TODO()
+			store := NewStore()
+			store.SetConflictPolicy(tt.policy, []string{"team-a", "team-b"})
+
+			store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}, first)
+			store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:02"}, second)
+
+			olt, err := store.getOlt(ctx, "BBSM_OLT_0")
+			if tt.missing {
+				assert.Error(t, err, "olt-not-found-in-store")
+			} else {
+				assert.NilError(t, err)
+				assert.Equal(t, olt.HardwareIdentifier, tt.served)
+			}
+
+			conflicts := store.listConflicts()
+			assert.Equal(t, len(conflicts), 1)
+			assert.Equal(t, conflicts[0].ID, "BBSM_OLT_0")
+			assert.Equal(t, len(conflicts[0].Owners), 2)
+
+			// once one of the pods goes away the conflict is solved
+			store.removeOwner(ctx, second)
+			assert.Equal(t, len(store.listConflicts()), 0)
+			olt, err = store.getOlt(ctx, "BBSM_OLT_0")
+			assert.NilError(t, err)
+			assert.Equal(t, olt.HardwareIdentifier, "00:00:00:00:00:01")
+		})
+	}
+}
+
+func Test_SameEntryIsNotAConflict(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	store.SetConflictPolicy(ConflictRejectBoth, nil)
+
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, Owner{Source: SourcePod, Name: "bbsim0"})
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, Owner{Source: SourcePod, Name: "bbsim1"})
+
+	_, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, len(store.listConflicts()), 0)
+}
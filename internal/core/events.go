@@ -0,0 +1,224 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// types of the changes to the served entries
+const (
+	EventAdd    = "add"
+	EventUpdate = "update"
+	EventDelete = "delete"
+)
+
+const (
+	// eventHistory is the number of events kept to resume a stream
+	eventHistory = 10000
+	// subscriberBuffer is the number of events a subscriber can lag behind before it's disconnected
+	subscriberBuffer = 1000
+	// revisionsPerMillisecond is used to make the revisions keep increasing across restarts,
+	// starting from the time the server starts, while still fitting in a JavaScript number
+	revisionsPerMillisecond = 1000
+	// eventsKeepAlive is how often a comment is sent on an idle stream, so that proxies don't close it
+	eventsKeepAlive = 15 * time.Second
+)
+
+// Event describes a change to an entry served to ONOS, for deletions Entry and Owner are the last served ones
+type Event struct {
+	// Revision is incremented for every change
+	Revision uint64      `json:"revision"`
+	Type     string      `json:"type"`
+	Kind     string      `json:"kind"`
+	ID       string      `json:"id"`
+	Entry    interface{} `json:"entry"`
+	Owner    Owner       `json:"owner"`
+}
+
+// eventLog assigns a revision to the events, keeps the most recent ones and delivers them to the subscribers
+type eventLog struct {
+	lock     sync.Mutex
+	revision uint64
+	// history is a ring buffer, the oldest event is at start
+	history []Event
+	start   int
+	size    int
+
+	subscribers map[chan Event]struct{}
+}
+
+// newEventLog creates a log whose first event has revision first+1
+func newEventLog(history int, first uint64) *eventLog {
+	return &eventLog{
+		revision:    first,
+		history:     make([]Event, history),
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+func (l *eventLog) append(ctx context.Context, event Event) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.revision++
+	event.Revision = l.revision
+
+	if l.size < len(l.history) {
+		l.history[(l.start+l.size)%len(l.history)] = event
+		l.size++
+	} else {
+		l.history[l.start] = event
+		l.start = (l.start + 1) % len(l.history)
+	}
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// the subscriber can't keep up, it can resume from the last event it received
+			logger.Warnw(ctx, "disconnecting-slow-events-subscriber", log.Fields{"revision": event.Revision})
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// current returns the revision of the last event
+func (l *eventLog) current() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.revision
+}
+
+// subscribe returns a channel on which the events are delivered and a function to stop receiving them,
+// if resume is true the events after the since revision are delivered first.
+// The channel is closed if the subscriber doesn't keep up with the events.
+func (l *eventLog) subscribe(since uint64, resume bool) (<-chan Event, func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	backlog := []Event{}
+	if resume {
+		// the events after since have been dropped from the history, or since comes from before a restart
+		if since < l.revision-uint64(l.size) || since > l.revision {
+			return nil, nil, fmt.Errorf("revision-%d-is-not-available", since)
+		}
+		for i := 0; i < l.size; i++ {
+			if event := l.history[(l.start+i)%len(l.history)]; event.Revision > since {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan Event, len(backlog)+subscriberBuffer)
+	for _, event := range backlog {
+		ch <- event
+	}
+	l.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		if _, ok := l.subscribers[ch]; ok {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
+
+// firstRevision is larger than any revision assigned before a restart, unless more than
+// revisionsPerMillisecond changes per millisecond of uptime were recorded
+func firstRevision() uint64 {
+	return uint64(time.Now().UnixNano()/int64(time.Millisecond)) * revisionsPerMillisecond
+}
+
+func (s *Store) subscribe(since uint64, resume bool) (<-chan Event, func(), error) {
+	return s.events.subscribe(since, resume)
+}
+
+// serveEvents streams the changes to the served entries as Server-Sent Events,
+// clients can resume from a revision with the Last-Event-ID header or the since parameter
+func (s Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(ctx, w, http.StatusInternalServerError, "Streaming is not supported.")
+		return
+	}
+
+	resumeFrom := r.Header.Get("Last-Event-ID")
+	if resumeFrom == "" {
+		resumeFrom = r.URL.Query().Get("since")
+	}
+	var since uint64
+	if resumeFrom != "" {
+		var err error
+		if since, err = strconv.ParseUint(resumeFrom, 10, 64); err != nil {
+			s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("Invalid revision %s.", resumeFrom))
+			return
+		}
+	}
+	logger.Debugw(ctx, "received-events-request", log.Fields{"since": resumeFrom})
+
+	events, cancel, err := s.store.memory().subscribe(since, resumeFrom != "")
+	if err != nil {
+		s.writeError(ctx, w, http.StatusGone, fmt.Sprintf("Revision %d is not available anymore, "+
+			"load the entries again and subscribe without a revision.", since))
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopping:
+			return
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Errorw(ctx, "cannot-encode-event", log.Fields{"revision": event.Revision, "err": err})
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
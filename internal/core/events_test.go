@@ -0,0 +1,184 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case event, ok := <-events:
+		assert.Assert(t, ok)
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	return Event{}
+}
+
+func Test_Events_store(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+
+	events, cancel, err := store.subscribe(0, false)
+	assert.NilError(t, err)
+	defer cancel()
+
+	first := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	second := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-1"}
+
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}, first)
+	event := nextEvent(t, events)
+	revision := event.Revision
+	assert.Assert(t, revision > 0)
+	assert.Equal(t, event.Type, EventAdd)
+	assert.Equal(t, event.Kind, EntryTypeOlt)
+	assert.Equal(t, event.ID, "BBSM_OLT_0")
+	assert.Equal(t, event.Owner, first)
+
+	// publishing the same entry again is not a change
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}, first)
+
+	// the same entry published by a new release of the pod is
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}, second)
+	event = nextEvent(t, events)
+	assert.Equal(t, event.Revision, revision+1)
+	assert.Equal(t, event.Type, EventUpdate)
+	assert.Equal(t, event.Owner, second)
+
+	store.removeOwner(ctx, first)
+	store.removeOwner(ctx, second)
+	event = nextEvent(t, events)
+	assert.Equal(t, event.Revision, revision+2)
+	assert.Equal(t, event.Type, EventDelete)
+	assert.Equal(t, event.Entry.(SadisOltEntry).HardwareIdentifier, "00:00:00:00:00:01")
+	assert.Equal(t, event.Owner, second)
+}
+
+func Test_Events_resume(t *testing.T) {
+	ctx := context.TODO()
+	log := newEventLog(2, 0)
+	for _, id := range []string{"a", "b", "c"} {
+		log.append(ctx, Event{Type: EventAdd, Kind: EntryTypeBp, ID: id})
+	}
+
+	// the first event is not in the history anymore
+	_, _, err := log.subscribe(0, true)
+	assert.ErrorContains(t, err, "revision-0-is-not-available")
+	// the revision is from the future
+	_, _, err = log.subscribe(4, true)
+	assert.ErrorContains(t, err, "revision-4-is-not-available")
+	// the server restarted, the new revisions are larger than the old ones
+	_, _, err = newEventLog(2, 1000).subscribe(3, true)
+	assert.ErrorContains(t, err, "revision-3-is-not-available")
+
+	events, cancel, err := log.subscribe(1, true)
+	assert.NilError(t, err)
+	defer cancel()
+	assert.Equal(t, nextEvent(t, events).ID, "b")
+	assert.Equal(t, nextEvent(t, events).ID, "c")
+
+	log.append(ctx, Event{Type: EventAdd, Kind: EntryTypeBp, ID: "d"})
+	event := nextEvent(t, events)
+	assert.Equal(t, event.ID, "d")
+	assert.Equal(t, event.Revision, uint64(4))
+}
+
+func Test_Events_http(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Name: "bbsim0"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, owner)
+	first := store.events.revision
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events?since=100")
+	assert.NilError(t, err)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusGone)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	assert.NilError(t, err)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(first-1, 10))
+	res, err = http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, res.Header.Get("Content-Type"), "text/event-stream")
+
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 2000}, owner)
+
+	reader := bufio.NewReader(res.Body)
+	for _, expected := range []struct {
+		eventType string
+		cir       int
+	}{{EventAdd, 1000}, {EventUpdate, 2000}} {
+		lines := map[string]string{}
+		for {
+			line, err := reader.ReadString('\n')
+			assert.NilError(t, err)
+			if line == "\n" {
+				break
+			}
+			parts := strings.SplitN(strings.TrimSuffix(line, "\n"), ": ", 2)
+			lines[parts[0]] = parts[1]
+		}
+		assert.Equal(t, lines["event"], expected.eventType)
+
+		var event struct {
+			Revision uint64
+			Entry    SadisBWPEntry
+		}
+		assert.NilError(t, json.Unmarshal([]byte(lines["data"]), &event))
+		assert.Equal(t, lines["id"], strconv.FormatUint(event.Revision, 10))
+		assert.Equal(t, event.Entry.CIR, expected.cir)
+	}
+}
+
+func Test_Events_shutdown(t *testing.T) {
+	cf := utils.NewConfigFlags()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- NewServer(NewStore(), nil, nil, cf).serve(ctx, listener, nil) }()
+
+	res, err := http.Get("http://" + listener.Addr().String() + "/events")
+	assert.NilError(t, err)
+	defer res.Body.Close()
+
+	// the stream doesn't delay the shutdown
+	start := time.Now()
+	cancel()
+	assert.NilError(t, <-stopped)
+	assert.Assert(t, time.Since(start) < cf.ShutdownTimeout)
+}
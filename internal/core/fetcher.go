@@ -0,0 +1,303 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backoffJitter is the maximum fraction of the backoff that is randomly added to it,
+// so that sources failing at the same time are not retried all together
+const backoffJitter = 0.5
+
+// sourceState tracks the fetches for a single owner
+type sourceState struct {
+	endpoint string
+	// active is false once the fetch has been cancelled, eg: because the pod is not ready anymore
+	active bool
+	// generation is incremented for every new request, so that an older attempt can detect it has been superseded
+	generation uint64
+	// cancel aborts the attempt in progress, if any
+	cancel context.CancelFunc
+
+	failures    int
+	lastError   error
+	lastSuccess time.Time
+	// nextAttempt is the earliest time at which a failing source is fetched again
+	nextAttempt time.Time
+}
+
+// Fetcher loads the SADIS configuration from BBSim instances with a bounded pool of workers,
+// so that a slow or failing instance does not delay the others
+type Fetcher struct {
+	store   Storage
+	config  *utils.ConfigFlags
+	client  *http.Client
+	queue   workqueue.DelayingInterface
+	backoff workqueue.RateLimiter
+
+	lock    sync.Mutex
+	sources map[Owner]*sourceState
+	workers sync.WaitGroup
+}
+
+func NewFetcher(store Storage, cf *utils.ConfigFlags) *Fetcher {
+	return &Fetcher{
+		store:   store,
+		config:  cf,
+		client:  &http.Client{Timeout: cf.FetchTimeout},
+		queue:   workqueue.NewDelayingQueue(),
+		backoff: workqueue.NewItemExponentialFailureRateLimiter(cf.FetchBackoffBase, cf.FetchBackoffMax),
+		sources: map[Owner]*sourceState{},
+	}
+}
+
+// Start runs the workers until ctx is done
+func (f *Fetcher) Start(ctx context.Context) {
+	logger.Infow(ctx, "starting-fetch-workers", log.Fields{"workers": f.config.FetchWorkers})
+	f.workers.Add(f.config.FetchWorkers)
+	for i := 0; i < f.config.FetchWorkers; i++ {
+		go func() {
+			defer f.workers.Done()
+			for f.processNext(ctx) {
+			}
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+		f.queue.ShutDown()
+	}()
+}
+
+// Fetch schedules the loading of the SADIS configuration for owner from endpoint,
+// an attempt already in progress for the same owner is superseded
+func (f *Fetcher) Fetch(ctx context.Context, owner Owner, endpoint string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	state, ok := f.sources[owner]
+	if !ok {
+		state = &sourceState{}
+		f.sources[owner] = state
+	}
+	if state.cancel != nil {
+		logger.Debugw(ctx, "superseding-fetch-in-progress", log.Fields{"owner": owner.String()})
+		state.cancel()
+		state.cancel = nil
+	}
+	state.generation++
+	state.endpoint = endpoint
+	state.active = true
+
+	// a failing source is not fetched again before its backoff expires
+	if delay := time.Until(state.nextAttempt); delay > 0 {
+		f.queue.AddAfter(owner, delay)
+		return
+	}
+	f.queue.Add(owner)
+}
+
+// Cancel aborts the pending fetches for owner, the failure history is kept
+func (f *Fetcher) Cancel(owner Owner) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if state, ok := f.sources[owner]; ok {
+		state.active = false
+		state.generation++
+		if state.cancel != nil {
+			state.cancel()
+			state.cancel = nil
+		}
+	}
+}
+
+// Forget aborts the pending fetches for owner and drops all the information about it
+func (f *Fetcher) Forget(owner Owner) {
+	f.Cancel(owner)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.sources, owner)
+	f.backoff.Forget(owner)
+	forgetSourceMetrics(owner)
+}
+
+// Wait blocks until the workers have stopped, so that nothing is written to the store anymore
+func (f *Fetcher) Wait() {
+	f.workers.Wait()
+}
+
+// settled returns true once each of the owners has been fetched successfully, or has failed ready_max_failures times
+// (BBSim may take a while to answer after its pod is ready). Owners that are not being fetched anymore
+// (eg: the pod has been deleted) are settled.
+func (f *Fetcher) settled(owners []Owner) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, owner := range owners {
+		state, ok := f.sources[owner]
+		if !ok || !state.active || !state.lastSuccess.IsZero() {
+			continue
+		}
+		if f.config.ReadyMaxFailures == 0 || state.failures < f.config.ReadyMaxFailures {
+			return false
+		}
+	}
+	return true
+}
+
+// loaded returns the number of sources that have been fetched successfully at least once
+func (f *Fetcher) loaded() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	count := 0
+	for _, state := range f.sources {
+		if !state.lastSuccess.IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *Fetcher) processNext(ctx context.Context) bool {
+	item, shutdown := f.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer f.queue.Done(item)
+
+	// the queue hands out the items left in it even after it has been shut down
+	if ctx.Err() != nil {
+		return false
+	}
+
+	owner := item.(Owner)
+
+	f.lock.Lock()
+	state, ok := f.sources[owner]
+	if !ok || !state.active {
+		f.lock.Unlock()
+		return true
+	}
+	attemptCtx, cancel := context.WithCancel(ctx)
+	state.cancel = cancel
+	generation := state.generation
+	endpoint := state.endpoint
+	f.lock.Unlock()
+
+	start := time.Now()
+	set, err := queryPod(attemptCtx, f.client, endpoint)
+	cancel()
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// the entries are stored while holding the lock, so that a source that
+	// has been forgotten in the meantime (eg: the pod was deleted) is not stored again
+	state, ok = f.sources[owner]
+	if !ok || state.generation != generation {
+		logger.Debugw(ctx, "fetch-has-been-superseded", log.Fields{"owner": owner.String(), "endpoint": endpoint})
+		return true
+	}
+	state.cancel = nil
+
+	// an attempt aborted because we're shutting down is not a failure of the source
+	if ctx.Err() != nil {
+		return false
+	}
+
+	fetchDuration.WithLabelValues(owner.String()).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		fetchFailuresTotal.WithLabelValues(owner.String()).Inc()
+		state.failures++
+		state.lastError = err
+		delay := wait.Jitter(f.backoff.When(owner), backoffJitter)
+		state.nextAttempt = time.Now().Add(delay)
+		logger.Warnw(ctx, "error-while-reading-from-service-retrying", log.Fields{"owner": owner.String(),
+			"endpoint": endpoint, "failures": state.failures, "retryIn": delay.String(), "error": err.Error()})
+		f.queue.AddAfter(owner, delay)
+		return true
+	}
+
+	state.failures = 0
+	state.lastError = nil
+	state.lastSuccess = time.Now()
+	fetchLastSuccess.WithLabelValues(owner.String()).Set(float64(state.lastSuccess.Unix()))
+	state.nextAttempt = time.Time{}
+	f.backoff.Forget(owner)
+
+	// only the differences with what was previously loaded from the same owner are applied
+	f.store.replaceOwner(ctx, owner, set)
+	logger.Infow(ctx, "stored-sadis-config", log.Fields{"owner": owner.String(), "endpoint": endpoint})
+	return true
+}
+
+// queryPod loads the SADIS configuration exposed by BBSim at endpoint (eg: http://10.0.0.1:50074)
+func queryPod(ctx context.Context, client *http.Client, endpoint string) (entrySet, error) {
+	logger.Infow(ctx, "querying-service", log.Fields{"endpoint": endpoint})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/v2/static", strings.TrimSuffix(endpoint, "/")), nil)
+	if err != nil {
+		return entrySet{}, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return entrySet{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return entrySet{}, fmt.Errorf("unexpected-status-code-%d", res.StatusCode)
+	}
+
+	var result SadisConfig
+
+	decoder := json.NewDecoder(res.Body)
+	if err := decoder.Decode(&result); err != nil {
+		logger.Errorw(ctx, "cannot-decode-sadis-response", log.Fields{"error": err.Error()})
+		return entrySet{}, err
+	}
+
+	logger.Debugw(ctx, "fetched-sadis-config", log.Fields{
+		"endpoint":          endpoint,
+		"entries":           len(result.Sadis.Entries),
+		"bandwidthProfiles": len(result.BandwidthProfile.Entries),
+	})
+
+	// every BBSim instance emulates a single OLT, all the ONUs it exposes are attached to it
+	set := newEntrySet(ctx, result)
+	if len(set.olts) == 1 {
+		set.olt = set.olts[0].ID
+	}
+	return set, nil
+}
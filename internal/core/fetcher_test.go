@@ -0,0 +1,142 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Fetcher_retriesWithBackoff(t *testing.T) {
+	config := SadisConfig{}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 1000}}
+
+	// the server fails the first requests, as BBSim does while it's starting
+	var requests int32
+	bbsim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(config)
+	}))
+	defer bbsim.Close()
+
+	cf := utils.NewConfigFlags()
+	cf.FetchBackoffBase = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	fetcher := NewFetcher(store, cf)
+	fetcher.Start(ctx)
+
+	owner := Owner{Source: SourceEndpoint, Name: bbsim.URL}
+	fetcher.Fetch(ctx, owner, bbsim.URL)
+
+	waitFor(t, func() bool { return store.hasOwner(owner) })
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(3))
+
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+	assert.Equal(t, fetcher.sources[owner].failures, 0)
+	assert.Assert(t, !fetcher.sources[owner].lastSuccess.IsZero())
+}
+
+func Test_Fetcher_forgetDropsInFlightAttempt(t *testing.T) {
+	config := SadisConfig{}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 1000}}
+
+	release := make(chan struct{})
+	bbsim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_ = json.NewEncoder(w).Encode(config)
+	}))
+	defer bbsim.Close()
+	other := newBBSimServer(config)
+	defer other.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// with a single worker the attempts are processed one after the other
+	cf := utils.NewConfigFlags()
+	cf.FetchWorkers = 1
+	store := NewStore()
+	fetcher := NewFetcher(store, cf)
+	fetcher.Start(ctx)
+
+	owner := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	fetcher.Fetch(ctx, owner, bbsim.URL)
+
+	// wait for the attempt to be in progress, then forget the source (eg: the pod was deleted)
+	waitFor(t, func() bool {
+		fetcher.lock.Lock()
+		defer fetcher.lock.Unlock()
+		return fetcher.sources[owner].cancel != nil
+	})
+	fetcher.Forget(owner)
+	close(release)
+
+	// once the next source has been fetched the forgotten attempt has ended
+	next := Owner{Source: SourceEndpoint, Name: other.URL}
+	fetcher.Fetch(ctx, next, other.URL)
+	waitFor(t, func() bool { return store.hasOwner(next) })
+
+	assert.Assert(t, !store.hasOwner(owner))
+	assert.Equal(t, len(store.listOwners()), 1)
+}
+
+func Test_Fetcher_stopsOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	bbsim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer bbsim.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := NewStore()
+	fetcher := NewFetcher(store, utils.NewConfigFlags())
+	fetcher.Start(ctx)
+
+	owner := Owner{Source: SourceEndpoint, Name: bbsim.URL}
+	fetcher.Fetch(ctx, owner, bbsim.URL)
+	waitFor(t, func() bool {
+		fetcher.lock.Lock()
+		defer fetcher.lock.Unlock()
+		return fetcher.sources[owner].cancel != nil
+	})
+
+	// the attempt in progress is aborted and the workers stop
+	cancel()
+	fetcher.Wait()
+
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+	assert.Equal(t, fetcher.sources[owner].failures, 0)
+	assert.Assert(t, !store.hasOwner(owner))
+}
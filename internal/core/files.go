@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileWatcher loads the SADIS documents (in the same format BBSim exposes on /v2/static)
+// stored as *.json files in a directory, and keeps the store in sync with them.
+// The directory is polled rather than watched via inotify, as ConfigMap volumes are updated
+// by swapping symlinks and inotify events are not reliable in that case.
+type FileWatcher struct {
+	store  Storage
+	config *utils.ConfigFlags
+	// loaded contains the checksum of the files that have been loaded in the store
+	loaded map[string][32]byte
+}
+
+func NewFileWatcher(store Storage, cf *utils.ConfigFlags) *FileWatcher {
+	return &FileWatcher{
+		store:  store,
+		config: cf,
+		loaded: map[string][32]byte{},
+	}
+}
+
+func (w *FileWatcher) Watch(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	logger.Infow(ctx, "watching-sadis-directory", log.Fields{"dir": w.config.SadisDir, "interval": w.config.SadisDirPollInterval})
+
+	ticker := time.NewTicker(w.config.SadisDirPollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.sync(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync loads new and modified files and removes the entries of the files that have been deleted
+func (w *FileWatcher) sync(ctx context.Context) {
+	files, err := filepath.Glob(filepath.Join(w.config.SadisDir, "*.json"))
+	if err != nil {
+		logger.Errorw(ctx, "cannot-list-sadis-directory", log.Fields{"dir": w.config.SadisDir, "err": err})
+		return
+	}
+
+	current := map[string]struct{}{}
+	for _, file := range files {
+		current[file] = struct{}{}
+		if err := w.loadFile(ctx, file); err != nil {
+			// keep serving what was loaded before, the file may be in the middle of being written
+			logger.Errorw(ctx, "cannot-load-sadis-file", log.Fields{"file": file, "err": err})
+		}
+	}
+
+	// the files restored from a persistent store are not in loaded
+	previous := map[string]struct{}{}
+	for file := range w.loaded {
+		previous[file] = struct{}{}
+	}
+	for _, owner := range w.store.listOwners() {
+		if owner.Source == SourceFile {
+			previous[owner.Name] = struct{}{}
+		}
+	}
+
+	for file := range previous {
+		if _, ok := current[file]; !ok {
+			logger.Infow(ctx, "sadis-file-removed", log.Fields{"file": file})
+			w.store.removeOwner(ctx, Owner{Source: SourceFile, Name: file})
+			delete(w.loaded, file)
+		}
+	}
+}
+
+func (w *FileWatcher) loadFile(ctx context.Context, file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	checksum := sha256.Sum256(content)
+	if previous, ok := w.loaded[file]; ok && previous == checksum {
+		return nil
+	}
+
+	var config SadisConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return err
+	}
+
+	logger.Infow(ctx, "loading-sadis-file", log.Fields{"file": file, "entries": len(config.Sadis.Entries),
+		"bandwidthProfiles": len(config.BandwidthProfile.Entries)})
+
+	w.store.replaceOwner(ctx, Owner{Source: SourceFile, Name: file}, newEntrySet(ctx, config))
+	w.loaded[file] = checksum
+	return nil
+}
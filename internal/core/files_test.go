@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSadisFile(t *testing.T, file string, config SadisConfig) {
+	content, err := json.Marshal(config)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(file, content, 0644))
+}
+
+func Test_FileWatcher_sync(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lab.json")
+
+	cf := utils.NewConfigFlags()
+	cf.SadisDir = dir
+
+	store := NewStore()
+	watcher := NewFileWatcher(store, cf)
+
+	// the same bandwidth profile is published by a BBSim pod, the file takes precedence
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, pod)
+
+	config := SadisConfig{}
+	config.Sadis.Entries = []*SadisEntry{
+		{ID: "LAB_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"},
+		{ID: "LAB_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10}}},
+	}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 2000}}
+	writeSadisFile(t, file, config)
+
+	watcher.sync(ctx)
+
+	_, err := store.getOlt(ctx, "LAB_OLT_0")
+	assert.NilError(t, err)
+	bp, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 2000)
+
+	// the ONU is removed from the file and the OLT is modified
+	config.Sadis.Entries = []*SadisEntry{
+		{ID: "LAB_OLT_0", HardwareIdentifier: "00:00:00:00:00:02"},
+	}
+	writeSadisFile(t, file, config)
+
+	watcher.sync(ctx)
+
+	olt, err := store.getOlt(ctx, "LAB_OLT_0")
+	assert.NilError(t, err)
+	assert.Equal(t, olt.HardwareIdentifier, "00:00:00:00:00:02")
+	_, err = store.getOnu(ctx, "LAB_ONU_0-1")
+	assert.Error(t, err, "onu-not-found-in-store")
+
+	// once the file is deleted the pod entry is served again
+	assert.NilError(t, os.Remove(file))
+
+	watcher.sync(ctx)
+
+	_, err = store.getOlt(ctx, "LAB_OLT_0")
+	assert.Error(t, err, "olt-not-found-in-store")
+	bp, err = store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 1000)
+}
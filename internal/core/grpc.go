@@ -0,0 +1,398 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/opencord/bbsim-sadis-server/api/sadis"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GrpcServer serves the same entries as the HTTP API, as typed protobuf messages
+type GrpcServer struct {
+	store  Storage
+	auth   *Auth
+	config *utils.ConfigFlags
+	// stopping is closed when the server is shut down, to end the Watch streams
+	stopping chan struct{}
+}
+
+// NewGrpcServer creates the gRPC server, if auth is nil the API can be read by anyone
+func NewGrpcServer(store Storage, auth *Auth, cf *utils.ConfigFlags) *GrpcServer {
+	if auth == nil {
+		auth = &Auth{anonymousRole: RoleRead}
+	}
+	return &GrpcServer{
+		store:    store,
+		auth:     auth,
+		config:   cf,
+		stopping: make(chan struct{}),
+	}
+}
+
+// StartGrpcServer serves the gRPC API until ctx is done, with the same TLS configuration as the HTTP API
+func (s *GrpcServer) StartGrpcServer(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	addr := net.JoinHostPort(s.config.ListenAddress, strconv.Itoa(s.config.GrpcPort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatalw(ctx, "cannot-listen", log.Fields{"addr": addr, "err": err})
+	}
+
+	var tlsConfig *tls.Config
+	if s.config.TLSCert != "" {
+		tlsConfig, err = newTLSConfig(s.config)
+		if err != nil {
+			logger.Fatalw(ctx, "cannot-load-tls-certificates", log.Fields{"cert": s.config.TLSCert, "key": s.config.TLSKey,
+				"clientCa": s.config.TLSClientCA, "err": err})
+		}
+		// the configuration is picked per connection, so it has to advertise HTTP/2 itself
+		getConfig := tlsConfig.GetConfigForClient
+		tlsConfig.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			config, err := getConfig(hello)
+			if err != nil {
+				return nil, err
+			}
+			config = config.Clone()
+			config.NextProtos = []string{"h2"}
+			return config, nil
+		}
+	}
+
+	logger.Infow(ctx, "starting-grpc-server", log.Fields{"addr": addr, "tls": tlsConfig != nil,
+		"mtls": s.config.TLSClientCA != ""})
+	if err := s.serve(ctx, listener, tlsConfig); err != nil {
+		logger.Fatal(ctx, err)
+	}
+}
+
+// serve handles the calls on listener until ctx is done, then stops accepting new calls
+// and waits up to the shutdown timeout for the ones in progress to complete
+func (s *GrpcServer) serve(ctx context.Context, listener net.Listener, tlsConfig *tls.Config) error {
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorize(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := s.authorize(stream.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(options...)
+	sadis.RegisterSadisServer(server, s)
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Infow(ctx, "stopping-grpc-server", log.Fields{"timeout": s.config.ShutdownTimeout.String()})
+	// GracefulStop waits for all the calls to return, so the streams are ended before it starts
+	close(s.stopping)
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		logger.Info(ctx, "grpc-server-stopped")
+	case <-time.After(s.config.ShutdownTimeout):
+		logger.Warn(ctx, "closing-grpc-calls-in-progress")
+		server.Stop()
+	}
+	return nil
+}
+
+// authorize checks the credentials in the authorization metadata, that are the same as in the
+// Authorization header of the HTTP API, all the calls require the read role
+func (s *GrpcServer) authorize(ctx context.Context, method string) error {
+	r := &http.Request{Header: http.Header{}}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			r.Header.Add("Authorization", value)
+		}
+	}
+
+	user, role, ok := s.auth.authenticate(ctx, r)
+	if !ok || (user == anonymousUser && roleRank(role) < roleRank(RoleRead)) {
+		return status.Error(codes.Unauthenticated, "Valid credentials are required.")
+	}
+	if roleRank(role) < roleRank(RoleRead) {
+		return status.Errorf(codes.PermissionDenied, "User %s is not allowed to access this resource.", user)
+	}
+
+	logger.Debugw(ctx, "authorized-call", log.Fields{"user": user, "role": role, "method": method})
+	return nil
+}
+
+func (s *GrpcServer) GetSubscriber(ctx context.Context, req *sadis.GetSubscriberRequest) (*sadis.Subscriber, error) {
+	logger.Debugw(ctx, "received-grpc-subscriber-request", log.Fields{"id": req.Id})
+
+	if olt, err := s.store.getOlt(ctx, req.Id); err == nil {
+		lookupsTotal.WithLabelValues("subscribers", lookupOltHit).Inc()
+		return &sadis.Subscriber{Entry: &sadis.Subscriber_Olt{Olt: toProtoOlt(*olt)}}, nil
+	}
+
+	if onu, err := s.store.getOnu(ctx, req.Id); err == nil {
+		lookupsTotal.WithLabelValues("subscribers", lookupOnuHit).Inc()
+		return &sadis.Subscriber{Entry: &sadis.Subscriber_Onu{Onu: toProtoOnu(*onu)}}, nil
+	}
+
+	lookupsTotal.WithLabelValues("subscribers", lookupNotFound).Inc()
+	logger.Warnw(ctx, "sadis-entry-not-found", log.Fields{"id": req.Id})
+	return nil, status.Errorf(codes.NotFound, "Entry with ID %s not found.", req.Id)
+}
+
+func (s *GrpcServer) GetBandwidthProfile(ctx context.Context, req *sadis.GetBandwidthProfileRequest) (*sadis.BandwidthProfile, error) {
+	logger.Debugw(ctx, "received-grpc-bandwidthprofile-request", log.Fields{"id": req.Id})
+
+	if bp, err := s.store.getBp(ctx, req.Id); err == nil {
+		lookupsTotal.WithLabelValues("profiles", lookupBpHit).Inc()
+		return toProtoBp(*bp), nil
+	}
+
+	lookupsTotal.WithLabelValues("profiles", lookupNotFound).Inc()
+	logger.Warnw(ctx, "sadis-bandwidthprofile-not-found", log.Fields{"id": req.Id})
+	return nil, status.Errorf(codes.NotFound, "BandwidthProfile with ID %s not found.", req.Id)
+}
+
+// List returns a page of the entries of the requested kind, with the same filters and pagination as the HTTP API
+func (s *GrpcServer) List(ctx context.Context, req *sadis.ListRequest) (*sadis.ListResponse, error) {
+	filters := map[string]string{}
+	for field, value := range map[string]string{
+		IndexCircuitID:          req.CircuitId,
+		IndexRemoteID:           req.RemoteId,
+		IndexNasPortID:          req.NasPortId,
+		IndexHardwareIdentifier: req.HardwareIdentifier,
+		IndexIPAddress:          req.IpAddress,
+		IndexNasID:              req.NasId,
+	} {
+		if value != "" {
+			filters[field] = value
+		}
+	}
+	logger.Debugw(ctx, "received-grpc-list-request", log.Fields{"kind": req.Kind.String(), "filters": filters,
+		"olt": req.OltId})
+
+	var ids []string
+	switch req.Kind {
+	case sadis.Kind_OLT:
+		if req.OltId != "" {
+			return nil, status.Error(codes.InvalidArgument, "olt_id can only be used to list ONUs.")
+		}
+		ids = intersect(matching(s.store.memory(), filters), s.store.listIDs(EntryTypeOlt), false)
+	case sadis.Kind_ONU:
+		ids = intersect(matching(s.store.memory(), filters), s.store.listIDs(EntryTypeOnu), false)
+		if req.OltId != "" {
+			ids = intersect(ids, s.store.memory().onusOf(req.OltId), false)
+		}
+	case sadis.Kind_BANDWIDTH_PROFILE:
+		if len(filters) > 0 || req.OltId != "" {
+			return nil, status.Error(codes.InvalidArgument, "Bandwidth profiles can't be filtered.")
+		}
+		ids = s.store.listIDs(EntryTypeBp)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown kind %d.", req.Kind)
+	}
+
+	limit := defaultPageLimit
+	if req.Limit != 0 {
+		limit = int(req.Limit)
+	}
+	page, next, err := pageOf(ids, limit, req.ContinueToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := &sadis.ListResponse{Total: int32(len(ids)), ContinueToken: next}
+	// the entries may have been removed after the IDs were listed
+	for _, id := range page {
+		switch req.Kind {
+		case sadis.Kind_OLT:
+			if olt, err := s.store.getOlt(ctx, id); err == nil {
+				res.Olts = append(res.Olts, toProtoOlt(*olt))
+			}
+		case sadis.Kind_ONU:
+			if onu, err := s.store.getOnu(ctx, id); err == nil {
+				res.Onus = append(res.Onus, toProtoOnu(*onu))
+			}
+		case sadis.Kind_BANDWIDTH_PROFILE:
+			if bp, err := s.store.getBp(ctx, id); err == nil {
+				res.BandwidthProfiles = append(res.BandwidthProfiles, toProtoBp(*bp))
+			}
+		}
+	}
+
+	logger.Infow(ctx, "responded-to-grpc-list-request", log.Fields{"kind": req.Kind.String(), "total": res.Total,
+		"entries": len(page)})
+	return res, nil
+}
+
+// Watch streams the changes to the served entries, starting after req.Since if set
+func (s *GrpcServer) Watch(req *sadis.WatchRequest, stream sadis.Sadis_WatchServer) error {
+	ctx := stream.Context()
+	logger.Debugw(ctx, "received-grpc-watch-request", log.Fields{"since": req.Since})
+
+	events, cancel, err := s.store.memory().subscribe(req.Since, req.Since != 0)
+	if err != nil {
+		return status.Errorf(codes.OutOfRange, "Revision %d is not available anymore, "+
+			"load the entries again and watch without a revision.", req.Since)
+	}
+	defer cancel()
+
+	// the headers tell the client that the changes from now on will be received
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "The server is shutting down.")
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Aborted, "The client is not keeping up with the changes, "+
+					"watch again from the last received revision.")
+			}
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoOlt(olt SadisOltEntry) *sadis.OltEntry {
+	return &sadis.OltEntry{
+		Id:                 olt.ID,
+		HardwareIdentifier: olt.HardwareIdentifier,
+		IpAddress:          olt.IPAddress,
+		NasId:              olt.NasID,
+		UplinkPort:         int32(olt.UplinkPort),
+		NniDhcpTrapVid:     int32(olt.NniDhcpTrapVid),
+	}
+}
+
+func toProtoOnu(onu SadisOnuEntryV2) *sadis.OnuEntry {
+	res := &sadis.OnuEntry{
+		Id:        onu.ID,
+		NasPortId: onu.NasPortID,
+		CircuitId: onu.CircuitID,
+		RemoteId:  onu.RemoteID,
+	}
+	for _, tag := range onu.UniTagList {
+		res.UniTagList = append(res.UniTagList, &sadis.UniTag{
+			UniTagMatch:                int32(tag.UniTagMatch),
+			PonCTag:                    int32(tag.PonCTag),
+			PonSTag:                    int32(tag.PonSTag),
+			TechnologyProfileId:        int32(tag.TechnologyProfileID),
+			UpstreamBandwidthProfile:   tag.UpstreamBandwidthProfile,
+			DownstreamBandwidthProfile: tag.DownstreamBandwidthProfile,
+			IsDhcpRequired:             tag.IsDhcpRequired,
+			IsIgmpRequired:             tag.IsIgmpRequired,
+			IsPppoeRequired:            tag.IsPPPoERequired,
+			ConfiguredMacAddress:       tag.ConfiguredMacAddress,
+			EnableMacLearning:          tag.EnableMacLearning,
+			UsPonCTagPriority:          uint32(tag.UsPonCTagPriority),
+			UsPonSTagPriority:          uint32(tag.UsPonSTagPriority),
+			DsPonCTagPriority:          uint32(tag.DsPonCTagPriority),
+			DsPonSTagPriority:          uint32(tag.DsPonSTagPriority),
+			ServiceName:                tag.ServiceName,
+		})
+	}
+	return res
+}
+
+func toProtoBp(bp SadisBWPEntry) *sadis.BandwidthProfile {
+	return &sadis.BandwidthProfile{
+		Id:  bp.ID,
+		Cbs: int64(bp.CBS),
+		Cir: int64(bp.CIR),
+		Air: int64(bp.AIR),
+		Ebs: int64(bp.EBS),
+		Eir: int64(bp.EIR),
+		Gir: int64(bp.GIR),
+		Pir: int64(bp.PIR),
+		Pbs: int64(bp.PBS),
+	}
+}
+
+func toProtoEvent(event Event) *sadis.Event {
+	res := &sadis.Event{
+		Revision: event.Revision,
+		Id:       event.ID,
+		Owner: &sadis.Owner{
+			Source:    event.Owner.Source,
+			Namespace: event.Owner.Namespace,
+			Name:      event.Owner.Name,
+			Uid:       event.Owner.UID,
+		},
+	}
+
+	switch event.Type {
+	case EventAdd:
+		res.Type = sadis.Event_ADD
+	case EventUpdate:
+		res.Type = sadis.Event_UPDATE
+	case EventDelete:
+		res.Type = sadis.Event_DELETE
+	}
+
+	switch entry := event.Entry.(type) {
+	case SadisOltEntry:
+		res.Kind = sadis.Kind_OLT
+		res.Entry = &sadis.Event_Olt{Olt: toProtoOlt(entry)}
+	case SadisOnuEntryV2:
+		res.Kind = sadis.Kind_ONU
+		res.Entry = &sadis.Event_Onu{Onu: toProtoOnu(entry)}
+	case SadisBWPEntry:
+		res.Kind = sadis.Kind_BANDWIDTH_PROFILE
+		res.Entry = &sadis.Event_BandwidthProfile{BandwidthProfile: toProtoBp(entry)}
+	}
+	return res
+}
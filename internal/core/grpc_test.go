@@ -0,0 +1,209 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"github.com/opencord/bbsim-sadis-server/api/sadis"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startTestGrpcServer(t *testing.T, store Storage, auth *Auth) (sadis.SadisClient, context.CancelFunc, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- NewGrpcServer(store, auth, utils.NewConfigFlags()).serve(ctx, listener, nil) }()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return sadis.NewSadisClient(conn), cancel, stopped
+}
+
+func Test_Grpc_get(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01", UplinkPort: 1048576}, owner)
+	store.addOnu(ctx, SadisOnuEntryV2{ID: "BBSM00000001-1", UniTagList: []SadisUniTag{
+		{PonCTag: 900, PonSTag: 900, TechnologyProfileID: 64, UsPonCTagPriority: 7, IsDhcpRequired: true},
+	}}, owner)
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000, PIR: 2000}, owner)
+
+	client, cancel, _ := startTestGrpcServer(t, store, nil)
+	defer cancel()
+
+	subscriber, err := client.GetSubscriber(ctx, &sadis.GetSubscriberRequest{Id: "BBSM_OLT_0"})
+	assert.NilError(t, err)
+	assert.Equal(t, subscriber.GetOlt().HardwareIdentifier, "00:00:00:00:00:01")
+	assert.Equal(t, subscriber.GetOlt().UplinkPort, int32(1048576))
+
+	subscriber, err = client.GetSubscriber(ctx, &sadis.GetSubscriberRequest{Id: "BBSM00000001-1"})
+	assert.NilError(t, err)
+	tag := subscriber.GetOnu().UniTagList[0]
+	assert.Equal(t, tag.PonCTag, int32(900))
+	assert.Equal(t, tag.TechnologyProfileId, int32(64))
+	assert.Equal(t, tag.UsPonCTagPriority, uint32(7))
+	assert.Equal(t, tag.IsDhcpRequired, true)
+
+	_, err = client.GetSubscriber(ctx, &sadis.GetSubscriberRequest{Id: "BBSM00000002-1"})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+
+	bp, err := client.GetBandwidthProfile(ctx, &sadis.GetBandwidthProfileRequest{Id: "Default"})
+	assert.NilError(t, err)
+	assert.Equal(t, bp.Cir, int64(1000))
+	assert.Equal(t, bp.Pir, int64(2000))
+
+	_, err = client.GetBandwidthProfile(ctx, &sadis.GetBandwidthProfileRequest{Id: "Missing"})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+}
+
+func Test_Grpc_list(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+
+	for _, name := range []string{"bbsim0", "bbsim1"} {
+		config := SadisConfig{}
+		config.Sadis.Entries = []*SadisEntry{
+			{ID: name + "_OLT", HardwareIdentifier: "00:00:00:00:00:01"},
+			{ID: name + "_ONU-1", NasPortID: name + "-1", CircuitID: "circuit", UniTagList: []SadisUniTag{{PonCTag: 10}}},
+			{ID: name + "_ONU-2", NasPortID: name + "-2", CircuitID: "circuit", UniTagList: []SadisUniTag{{PonCTag: 11}}},
+		}
+		set := newEntrySet(ctx, config)
+		set.olt = name + "_OLT"
+		store.replaceOwner(ctx, Owner{Source: SourcePod, Name: name}, set)
+	}
+
+	client, cancel, _ := startTestGrpcServer(t, store, nil)
+	defer cancel()
+
+	res, err := client.List(ctx, &sadis.ListRequest{Kind: sadis.Kind_OLT, HardwareIdentifier: "00:00:00:00:00:01"})
+	assert.NilError(t, err)
+	assert.Equal(t, res.Total, int32(2))
+	assert.Equal(t, len(res.Olts), 2)
+	assert.Equal(t, len(res.Onus), 0)
+
+	res, err = client.List(ctx, &sadis.ListRequest{Kind: sadis.Kind_ONU, CircuitId: "circuit", OltId: "bbsim1_OLT", Limit: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, res.Total, int32(2))
+	assert.Equal(t, res.Onus[0].Id, "bbsim1_ONU-1")
+	assert.Assert(t, res.ContinueToken != "")
+
+	res, err = client.List(ctx, &sadis.ListRequest{Kind: sadis.Kind_ONU, CircuitId: "circuit", OltId: "bbsim1_OLT",
+		Limit: 1, ContinueToken: res.ContinueToken})
+	assert.NilError(t, err)
+	assert.Equal(t, res.Onus[0].Id, "bbsim1_ONU-2")
+	assert.Equal(t, res.ContinueToken, "")
+
+	_, err = client.List(ctx, &sadis.ListRequest{Kind: sadis.Kind_BANDWIDTH_PROFILE, CircuitId: "circuit"})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	_, err = client.List(ctx, &sadis.ListRequest{Kind: sadis.Kind_ONU, Limit: maxPageLimit + 1})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+}
+
+func Test_Grpc_watch(t *testing.T) {
+	ctx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Name: "bbsim0"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, owner)
+	first := store.events.current()
+
+	client, cancel, stopped := startTestGrpcServer(t, store, nil)
+
+	stream, err := client.Watch(ctx, &sadis.WatchRequest{})
+	assert.NilError(t, err)
+	// the stream is established once the headers are received
+	_, err = stream.Header()
+	assert.NilError(t, err)
+
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 2000}, owner)
+	event, err := stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, event.Type, sadis.Event_UPDATE)
+	assert.Equal(t, event.Kind, sadis.Kind_BANDWIDTH_PROFILE)
+	assert.Equal(t, event.GetBandwidthProfile().Cir, int64(2000))
+	assert.Equal(t, event.Owner.Name, "bbsim0")
+
+	// a client can resume from the last revision it received
+	resumed, err := client.Watch(ctx, &sadis.WatchRequest{Since: first})
+	assert.NilError(t, err)
+	event, err = resumed.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, event.Revision, first+1)
+
+	missing, err := client.Watch(ctx, &sadis.WatchRequest{Since: first + 100})
+	assert.NilError(t, err)
+	_, err = missing.Recv()
+	assert.Equal(t, status.Code(err), codes.OutOfRange)
+
+	// the streams don't delay the shutdown
+	start := time.Now()
+	cancel()
+	assert.NilError(t, <-stopped)
+	assert.Assert(t, time.Since(start) < utils.NewConfigFlags().ShutdownTimeout)
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.Unavailable)
+}
+
+func Test_Grpc_auth(t *testing.T) {
+	ctx := context.TODO()
+	file := filepath.Join(t.TempDir(), "tokens")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("secret:read\n"), 0600))
+
+	cf := utils.NewConfigFlags()
+	cf.AuthTokensFile = file
+	cf.AuthAnonymousRole = RoleNone
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+
+	store := NewStore()
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, Owner{Source: SourcePod, Name: "bbsim0"})
+
+	client, cancel, _ := startTestGrpcServer(t, store, auth)
+	defer cancel()
+
+	_, err = client.GetBandwidthProfile(ctx, &sadis.GetBandwidthProfileRequest{Id: "Default"})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	wrong := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong")
+	_, err = client.GetBandwidthProfile(wrong, &sadis.GetBandwidthProfileRequest{Id: "Default"})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	valid := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	_, err = client.GetBandwidthProfile(valid, &sadis.GetBandwidthProfileRequest{Id: "Default"})
+	assert.NilError(t, err)
+
+	stream, err := client.Watch(ctx, &sadis.WatchRequest{})
+	assert.NilError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Health backs the liveness and readiness probes
+type Health struct {
+	discovery Discovery
+	fetcher   *Fetcher
+	config    *utils.ConfigFlags
+
+	lock sync.Mutex
+	// components contains the long running goroutines and whether they are still running
+	components map[string]bool
+	// ready is latched, so that pods coming and going after startup don't make the server unready
+	ready bool
+}
+
+func NewHealth(discovery Discovery, fetcher *Fetcher, cf *utils.ConfigFlags) *Health {
+	return &Health{
+		discovery:  discovery,
+		fetcher:    fetcher,
+		config:     cf,
+		components: map[string]bool{},
+	}
+}
+
+// Run runs f, the server is not alive anymore once it returns
+func (h *Health) Run(name string, f func()) {
+	h.lock.Lock()
+	h.components[name] = true
+	h.lock.Unlock()
+
+	defer func() {
+		h.lock.Lock()
+		h.components[name] = false
+		h.lock.Unlock()
+		logger.Warnw(context.Background(), "component-stopped", log.Fields{"component": name})
+	}()
+	f()
+}
+
+// stopped returns the components that are not running anymore
+func (h *Health) stopped() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	res := []string{}
+	for name, running := range h.components {
+		if !running {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// isReady returns true once the BBSim instances found at startup have been loaded (see Fetcher.settled),
+// or once the configured minimum number of them has been loaded
+func (h *Health) isReady() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.ready {
+		return true
+	}
+	if h.config.ReadyMinSources > 0 && h.fetcher.loaded() >= h.config.ReadyMinSources {
+		h.ready = true
+	} else if h.discovery.Synced() {
+		h.ready = true
+	}
+	return h.ready
+}
+
+func (s Server) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	stopped := s.health.stopped()
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(stopped) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"alive": len(stopped) == 0, "stopped": stopped})
+}
+
+func (s Server) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	ready := s.health.isReady()
+
+	w.Header().Set("Content-Type", "application/json")
+	if ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ready": ready})
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Readiness(t *testing.T) {
+	bbsim := newBBSimServer(SadisConfig{})
+	defer bbsim.Close()
+
+	// an endpoint that never answers
+	blocked := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer slow.Close()
+	defer close(blocked)
+
+	tests := []struct {
+		name       string
+		minSources int
+		ready      bool
+	}{
+		{"all-sources", 0, false},
+		{"min-sources", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := utils.NewConfigFlags()
+			cf.StaticEndpoints = []string{bbsim.URL, slow.URL}
+			cf.FetchTimeout = time.Minute
+			cf.ReadyMinSources = tt.minSources
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			store := NewStore()
+			fetcher := NewFetcher(store, cf)
+			fetcher.Start(ctx)
+			watcher := NewStaticWatcher(store, fetcher, cf)
+			health := NewHealth(watcher, fetcher, cf)
+
+			server := httptest.NewServer(NewServer(store, health, nil, cf).router())
+			defer server.Close()
+
+			status, _ := getPage(t, server.URL+"/readyz")
+			assert.Equal(t, status, http.StatusServiceUnavailable)
+
+			watcher.poll(ctx)
+			waitFor(t, func() bool { return fetcher.loaded() == 1 })
+
+			status, _ = getPage(t, server.URL+"/readyz")
+			assert.Equal(t, status == http.StatusOK, tt.ready)
+
+			// once the slow endpoint is removed all the initial endpoints are settled
+			fetcher.Forget(Owner{Source: SourceEndpoint, Name: slow.URL})
+			status, _ = getPage(t, server.URL+"/readyz")
+			assert.Equal(t, status, http.StatusOK)
+		})
+	}
+}
+
+func Test_Readiness_failingSource(t *testing.T) {
+	config := SadisConfig{}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 1000}}
+
+	tests := []struct {
+		name        string
+		maxFailures int
+		ready       bool
+	}{
+		{"wait-for-success", 0, false},
+		{"max-failures", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// BBSim fails the first requests, as it does while its REST server is starting
+			var failing int32 = 1
+			bbsim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.LoadInt32(&failing) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(config)
+			}))
+			defer bbsim.Close()
+
+			cf := utils.NewConfigFlags()
+			cf.StaticEndpoints = []string{bbsim.URL}
+			cf.FetchBackoffBase = 5 * time.Millisecond
+			cf.FetchBackoffMax = 10 * time.Millisecond
+			cf.ReadyMaxFailures = tt.maxFailures
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			store := NewStore()
+			fetcher := NewFetcher(store, cf)
+			fetcher.Start(ctx)
+			watcher := NewStaticWatcher(store, fetcher, cf)
+			health := NewHealth(watcher, fetcher, cf)
+
+			server := httptest.NewServer(NewServer(store, health, nil, cf).router())
+			defer server.Close()
+
+			watcher.poll(ctx)
+			owner := Owner{Source: SourceEndpoint, Name: bbsim.URL}
+			waitFor(t, func() bool {
+				fetcher.lock.Lock()
+				defer fetcher.lock.Unlock()
+				return fetcher.sources[owner].failures >= 3
+			})
+
+			status, _ := getPage(t, server.URL+"/readyz")
+			assert.Equal(t, status == http.StatusOK, tt.ready)
+
+			// the server is ready once BBSim has been loaded
+			atomic.StoreInt32(&failing, 0)
+			waitFor(t, func() bool { return store.hasOwner(owner) })
+			status, _ = getPage(t, server.URL+"/readyz")
+			assert.Equal(t, status, http.StatusOK)
+		})
+	}
+}
+
+func Test_Liveness(t *testing.T) {
+	cf := utils.NewConfigFlags()
+	health := NewHealth(nil, nil, cf)
+	server := httptest.NewServer(NewServer(NewStore(), health, nil, cf).router())
+	defer server.Close()
+
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		health.Run("discovery", func() { <-stop })
+		close(done)
+	}()
+	waitFor(t, func() bool {
+		health.lock.Lock()
+		defer health.lock.Unlock()
+		return health.components["discovery"]
+	})
+
+	status, _ := getPage(t, server.URL+"/healthz")
+	assert.Equal(t, status, http.StatusOK)
+
+	close(stop)
+	<-done
+
+	status, _ = getPage(t, server.URL+"/healthz")
+	assert.Equal(t, status, http.StatusServiceUnavailable)
+}
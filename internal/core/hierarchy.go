@@ -0,0 +1,119 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// oltOf returns the ID of the OLT the served ONU is attached to, that is the OLT published together with it
+// by a BBSim instance. The ONU may be served with the value of another owner (eg: an override or a file),
+// the one published together with the OLT is preferred if more instances publish the same ONU.
+func (s *Store) oltOf(onuID string) (string, bool) {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	served, ok := s.onus.Load(onuID)
+	if !ok {
+		return "", false
+	}
+
+	// all the owners publishing the ONU are considered, not only the ones it can be served from
+	publishers := s.onuTable.owners[onuID]
+	found := ""
+	for _, owner := range sortedOwners(publishers) {
+		parent, ok := s.parents[owner]
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(publishers[owner].value, served) {
+			return parent, true
+		}
+		if found == "" {
+			found = parent
+		}
+	}
+	return found, found != ""
+}
+
+// onusOf returns the IDs (sorted) of the served ONUs that are attached to the OLT
+func (s *Store) onusOf(oltID string) []string {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	found := map[string]struct{}{}
+	for owner, parent := range s.parents {
+		if parent != oltID {
+			continue
+		}
+		for id := range s.onuTable.owners.published(owner) {
+			// the ONU may be served with the value published by another owner (eg: an override)
+			if _, ok := s.onus.Load(id); ok {
+				found[id] = struct{}{}
+			}
+		}
+	}
+
+	ids := []string{}
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s Server) serveOltOnus(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["ID"]
+
+	ctx := r.Context()
+	logger.Debugw(ctx, "received-olt-onus-request", log.Fields{"olt": id})
+
+	if _, err := s.store.getOlt(r.Context(), id); err != nil {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("OLT with ID %s not found.", id))
+		return
+	}
+
+	s.serveList(ctx, w, r, EntryTypeOnu, s.store.memory().onusOf(id), func(id string) (interface{}, error) {
+		return s.store.getOnu(r.Context(), id)
+	})
+}
+
+func (s Server) serveOnuOlt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["ID"]
+
+	ctx := r.Context()
+	logger.Debugw(ctx, "received-onu-olt-request", log.Fields{"onu": id})
+
+	parent, ok := s.store.memory().oltOf(id)
+	if !ok {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("OLT for ONU with ID %s not found.", id))
+		return
+	}
+	olt, err := s.store.getOlt(r.Context(), parent)
+	if err != nil {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("OLT with ID %s not found.", parent))
+		return
+	}
+
+	s.writeCacheable(w, r, olt, false)
+	logger.Infow(ctx, "responded-to-onu-olt-request", log.Fields{"onu": id, "olt": parent})
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Hierarchy(t *testing.T) {
+	ctx := context.TODO()
+
+	config := SadisConfig{}
+	config.Sadis.Entries = []*SadisEntry{
+		{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"},
+		{ID: "BBSM_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10}}},
+		{ID: "BBSM_ONU_0-2", UniTagList: []SadisUniTag{{PonCTag: 11}}},
+	}
+	bbsim := newBBSimServer(config)
+	defer bbsim.Close()
+
+	set, err := queryPod(ctx, http.DefaultClient, bbsim.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, set.olt, "BBSM_OLT_0")
+
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.replaceOwner(ctx, pod, set)
+
+	parent, ok := store.oltOf("BBSM_ONU_0-1")
+	assert.Assert(t, ok)
+	assert.Equal(t, parent, "BBSM_OLT_0")
+	assert.DeepEqual(t, store.onusOf("BBSM_OLT_0"), []string{"BBSM_ONU_0-1", "BBSM_ONU_0-2"})
+
+	// an ONU that is served from a file is still attached to the OLT
+	file := Owner{Source: SourceFile, Name: "/etc/sadis/lab.json"}
+	store.addOnu(ctx, SadisOnuEntryV2{ID: "BBSM_ONU_0-2", UniTagList: []SadisUniTag{{PonCTag: 20}}}, file)
+	assert.DeepEqual(t, store.onusOf("BBSM_OLT_0"), []string{"BBSM_ONU_0-1", "BBSM_ONU_0-2"})
+	parent, ok = store.oltOf("BBSM_ONU_0-2")
+	assert.Assert(t, ok)
+	assert.Equal(t, parent, "BBSM_OLT_0")
+
+	// an ONU only published by a file is not attached to any OLT
+	store.addOnu(ctx, SadisOnuEntryV2{ID: "LAB_ONU-1", UniTagList: []SadisUniTag{{PonCTag: 30}}}, file)
+	_, ok = store.oltOf("LAB_ONU-1")
+	assert.Assert(t, !ok)
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	status, page := getPage(t, server.URL+"/olts/BBSM_OLT_0/onus")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, page.Total, 2)
+	assert.Equal(t, page.Entries[0]["id"], "BBSM_ONU_0-1")
+	assert.Equal(t, page.Entries[1]["id"], "BBSM_ONU_0-2")
+
+	res, err := http.Get(server.URL + "/onus/BBSM_ONU_0-1/olt")
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	olt := SadisOltEntry{}
+	assert.NilError(t, json.NewDecoder(res.Body).Decode(&olt))
+	assert.Equal(t, olt.ID, "BBSM_OLT_0")
+
+	status, _ = getPage(t, server.URL+"/olts/BBSM_OLT_1/onus")
+	assert.Equal(t, status, http.StatusNotFound)
+	status, _ = getPage(t, server.URL+"/onus/LAB_ONU-1/olt")
+	assert.Equal(t, status, http.StatusNotFound)
+
+	// the relationship goes away with the pod
+	store.removeOwner(ctx, pod)
+	assert.DeepEqual(t, store.onusOf("BBSM_OLT_0"), []string{})
+}
+
+func Test_Hierarchy_overrides(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.replaceOwner(ctx, pod, entrySet{
+		olt:  "BBSM_OLT_0",
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}},
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10}}}},
+	})
+
+	cf := utils.NewConfigFlags()
+	cf.AdminToken = "secret"
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+	server := httptest.NewServer(NewServer(store, nil, auth, cf).router())
+	defer server.Close()
+
+	// the overridden ONU is still attached to the OLT of the BBSim instance publishing it
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		assert.Equal(t, adminRequest(t, method, server.URL+"/subscribers/BBSM_ONU_0-1", "secret",
+			`{"uniTagList": [{"ponCTag": 20}]}`), http.StatusOK)
+
+		status, page := getPage(t, server.URL+"/olts/BBSM_OLT_0/onus")
+		assert.Equal(t, status, http.StatusOK)
+		assert.Equal(t, page.Total, 1)
+		assert.Equal(t, page.Entries[0]["id"], "BBSM_ONU_0-1")
+
+		res, err := http.Get(server.URL + "/onus/BBSM_ONU_0-1/olt")
+		assert.NilError(t, err)
+		olt := SadisOltEntry{}
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&olt))
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, olt.ID, "BBSM_OLT_0")
+	}
+}
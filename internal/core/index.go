@@ -0,0 +1,99 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"sort"
+)
+
+// fields of the served entries that can be used to look them up, named after their JSON representation
+const (
+	IndexCircuitID          = "circuitId"
+	IndexRemoteID           = "remoteId"
+	IndexNasPortID          = "nasPortId"
+	IndexHardwareIdentifier = "hardwareIdentifier"
+	IndexIPAddress          = "ipAddress"
+	IndexNasID              = "nasId"
+)
+
+// IndexedFields lists all the fields that can be used to look up entries
+var IndexedFields = []string{IndexCircuitID, IndexRemoteID, IndexNasPortID, IndexHardwareIdentifier, IndexIPAddress, IndexNasID}
+
+// index maps the values of a field to the IDs of the served entries with that value
+type index map[string]map[string]struct{}
+
+func (i index) add(value string, id string) {
+	if _, ok := i[value]; !ok {
+		i[value] = map[string]struct{}{}
+	}
+	i[value][id] = struct{}{}
+}
+
+func (i index) remove(value string, id string) {
+	delete(i[value], id)
+	if len(i[value]) == 0 {
+		delete(i, value)
+	}
+}
+
+// indexedValues returns the value of the indexed fields of an entry, empty fields are not indexed
+func indexedValues(entry interface{}) map[string]string {
+	values := map[string]string{}
+	switch e := entry.(type) {
+	case SadisOltEntry:
+		values[IndexHardwareIdentifier] = e.HardwareIdentifier
+		values[IndexIPAddress] = e.IPAddress
+		values[IndexNasID] = e.NasID
+	case SadisOnuEntryV2:
+		values[IndexCircuitID] = e.CircuitID
+		values[IndexRemoteID] = e.RemoteID
+		values[IndexNasPortID] = e.NasPortID
+	}
+	for field, value := range values {
+		if value == "" {
+			delete(values, field)
+		}
+	}
+	return values
+}
+
+// reindex updates the indexes when the entry served for id changes from old to new,
+// either of them is nil if the entry didn't exist or has been removed
+func (s *Store) reindex(id string, old interface{}, new interface{}) {
+	s.indexLock.Lock()
+	defer s.indexLock.Unlock()
+
+	for field, value := range indexedValues(old) {
+		s.indexes[field].remove(value, id)
+	}
+	for field, value := range indexedValues(new) {
+		s.indexes[field].add(value, id)
+	}
+}
+
+// lookup returns the IDs (sorted) of the served entries where field has the given value
+func (s *Store) lookup(field string, value string) []string {
+	s.indexLock.RLock()
+	defer s.indexLock.RUnlock()
+
+	ids := []string{}
+	for id := range s.indexes[field][value] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_lookup(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	file := Owner{Source: SourceFile, Name: "/etc/sadis/lab.json"}
+
+	store.replaceOwner(ctx, pod, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "0f:f1:ce:c0:ff:ee", IPAddress: "10.0.0.1"}},
+		onus: []SadisOnuEntryV2{
+			{ID: "BBSM_ONU_0-1", CircuitID: "BBSM_ONU_0-1-1", RemoteID: "BBSM_ONU_0-1", NasPortID: "BBSM_ONU_0-1-1"},
+			{ID: "BBSM_ONU_0-2", CircuitID: "BBSM_ONU_0-2-1", RemoteID: "BBSM_ONU_0-1", NasPortID: "BBSM_ONU_0-2-1"},
+		},
+	})
+
+	assert.DeepEqual(t, store.lookup(IndexHardwareIdentifier, "0f:f1:ce:c0:ff:ee"), []string{"BBSM_OLT_0"})
+	assert.DeepEqual(t, store.lookup(IndexIPAddress, "10.0.0.1"), []string{"BBSM_OLT_0"})
+	assert.DeepEqual(t, store.lookup(IndexRemoteID, "BBSM_ONU_0-1"), []string{"BBSM_ONU_0-1", "BBSM_ONU_0-2"})
+	assert.DeepEqual(t, store.lookup(IndexCircuitID, "BBSM_ONU_0-2-1"), []string{"BBSM_ONU_0-2"})
+	assert.DeepEqual(t, store.lookup(IndexNasID, ""), []string{})
+
+	// a replaced entry is only found by its new values
+	store.replaceOwner(ctx, pod, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "0f:f1:ce:c0:ff:ee", IPAddress: "10.0.0.2"}},
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", CircuitID: "BBSM_ONU_0-1-1", RemoteID: "BBSM_ONU_0-1"}},
+	})
+	assert.DeepEqual(t, store.lookup(IndexIPAddress, "10.0.0.1"), []string{})
+	assert.DeepEqual(t, store.lookup(IndexIPAddress, "10.0.0.2"), []string{"BBSM_OLT_0"})
+	assert.DeepEqual(t, store.lookup(IndexRemoteID, "BBSM_ONU_0-1"), []string{"BBSM_ONU_0-1"})
+	assert.DeepEqual(t, store.lookup(IndexNasPortID, "BBSM_ONU_0-1-1"), []string{})
+
+	// only the served value is indexed
+	store.addOnu(ctx, SadisOnuEntryV2{ID: "BBSM_ONU_0-1", CircuitID: "lab-circuit"}, file)
+	assert.DeepEqual(t, store.lookup(IndexCircuitID, "BBSM_ONU_0-1-1"), []string{})
+	assert.DeepEqual(t, store.lookup(IndexCircuitID, "lab-circuit"), []string{"BBSM_ONU_0-1"})
+
+	store.removeOwner(ctx, file)
+	store.removeOwner(ctx, pod)
+	for _, field := range IndexedFields {
+		assert.Equal(t, len(store.indexes[field]), 0, field)
+	}
+}
+
+func Test_serveEntries(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	store.replaceOwner(ctx, Owner{Source: SourcePod, Name: "bbsim0"}, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "0f:f1:ce:c0:ff:ee"}},
+		onus: []SadisOnuEntryV2{
+			{ID: "BBSM_ONU_0-1", CircuitID: "BBSM_ONU_0-1-1", RemoteID: "BBSM_ONU_0-1"},
+			{ID: "BBSM_ONU_0-2", CircuitID: "BBSM_ONU_0-2-1", RemoteID: "BBSM_ONU_0-1"},
+		},
+	})
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	get := func(query string) (int, []map[string]interface{}) {
+		res, err := http.Get(server.URL + "/subscribers" + query)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		body := struct {
+			Entries []map[string]interface{} `json:"entries"`
+		}{}
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		return res.StatusCode, body.Entries
+	}
+
+	status, entries := get("?remoteId=BBSM_ONU_0-1&circuitId=BBSM_ONU_0-2-1")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0]["id"], "BBSM_ONU_0-2")
+
+	status, entries = get("?hardwareIdentifier=0f:f1:ce:c0:ff:ee")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0]["id"], "BBSM_OLT_0")
+
+	status, entries = get("?circuitId=unknown")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, len(entries), 0)
+
+	// without filters all the entries are listed
+	status, entries = get("")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, len(entries), 3)
+}
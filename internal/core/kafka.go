@@ -0,0 +1,217 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Shopify/sarama"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"sync"
+	"time"
+)
+
+// EventSnapshot is the type of the Kafka messages containing all the served entries
+const EventSnapshot = "snapshot"
+
+const (
+	kafkaClientID = "bbsim-sadis-server"
+	// kafkaRetryInterval is how often the connection to the brokers, or a failed message, is retried
+	kafkaRetryInterval = 5 * time.Second
+)
+
+// snapshotMessage is published periodically, so that consumers that join late
+// or missed some changes can rebuild the whole state
+type snapshotMessage struct {
+	Revision uint64   `json:"revision"`
+	Type     string   `json:"type"`
+	Snapshot Snapshot `json:"snapshot"`
+}
+
+// KafkaPublisher publishes the changes to the served entries on a Kafka topic, as JSON documents
+type KafkaPublisher struct {
+	store  Storage
+	config *utils.ConfigFlags
+	// newProducer connects to the brokers
+	newProducer func() (sarama.SyncProducer, error)
+	// retryInterval is how long to wait before retrying a failed connection or message
+	retryInterval time.Duration
+}
+
+func NewKafkaPublisher(store Storage, cf *utils.ConfigFlags) *KafkaPublisher {
+	return &KafkaPublisher{
+		store:  store,
+		config: cf,
+		newProducer: func() (sarama.SyncProducer, error) {
+			config := sarama.NewConfig()
+			config.ClientID = kafkaClientID
+			config.Producer.RequiredAcks = sarama.WaitForLocal
+			// required by the SyncProducer
+			config.Producer.Return.Successes = true
+			return sarama.NewSyncProducer(cf.KafkaAddresses, config)
+		},
+		retryInterval: kafkaRetryInterval,
+	}
+}
+
+// Publish sends an event for every change to the served entries until ctx is done,
+// all the entries are published at startup and then every KafkaSnapshotPeriod
+func (p *KafkaPublisher) Publish(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	producer := p.connect(ctx)
+	if producer == nil {
+		return
+	}
+	defer producer.Close()
+
+	logger.Infow(ctx, "publishing-changes-on-kafka", log.Fields{"addresses": p.config.KafkaAddresses,
+		"topic": p.config.KafkaTopic, "snapshotPeriod": p.config.KafkaSnapshotPeriod.String()})
+
+	var snapshots <-chan time.Time
+	if p.config.KafkaSnapshotPeriod > 0 {
+		ticker := time.NewTicker(p.config.KafkaSnapshotPeriod)
+		defer ticker.Stop()
+		snapshots = ticker.C
+	}
+
+	var events <-chan Event
+	cancel := func() {}
+	defer func() { cancel() }()
+
+	// last is the revision of the last change that has been published
+	var last uint64
+	for {
+		if events == nil {
+			var err error
+			if events, cancel, last, err = p.subscribe(ctx, producer, last); err != nil {
+				if !p.wait(ctx) {
+					return
+				}
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-snapshots:
+			_, _ = p.publishSnapshot(ctx, producer)
+		case event, ok := <-events:
+			if !ok {
+				// we didn't keep up with the changes (eg: the brokers are slow), resubscribe
+				events = nil
+				continue
+			}
+			// the changes that happened before a snapshot are already included in it
+			if event.Revision <= last {
+				continue
+			}
+			if err := p.send(ctx, producer, event.Type, event.Kind+"/"+event.ID, event); err != nil {
+				// the change is sent again once we resubscribe after the last published revision
+				cancel()
+				events = nil
+				if !p.wait(ctx) {
+					return
+				}
+				continue
+			}
+			last = event.Revision
+		}
+	}
+}
+
+// wait waits before retrying a failed message, it returns false if ctx is done before
+func (p *KafkaPublisher) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(p.retryInterval):
+		return true
+	}
+}
+
+// connect retries to connect to the brokers until it succeeds, it returns nil if ctx is done before
+func (p *KafkaPublisher) connect(ctx context.Context) sarama.SyncProducer {
+	for {
+		producer, err := p.newProducer()
+		if err == nil {
+			return producer
+		}
+		logger.Warnw(ctx, "cannot-connect-to-kafka-retrying", log.Fields{"addresses": p.config.KafkaAddresses,
+			"retryIn": p.retryInterval.String(), "err": err})
+
+		if !p.wait(ctx) {
+			return nil
+		}
+	}
+}
+
+// subscribe resumes the changes after the last published revision,
+// if they're not available anymore all the entries are published again
+func (p *KafkaPublisher) subscribe(ctx context.Context, producer sarama.SyncProducer,
+	last uint64) (<-chan Event, func(), uint64, error) {
+	if last != 0 {
+		if events, cancel, err := p.store.memory().subscribe(last, true); err == nil {
+			return events, cancel, last, nil
+		}
+		logger.Warnw(ctx, "changes-not-published-on-kafka-sending-snapshot", log.Fields{"revision": last})
+	}
+
+	// subscribing before taking the snapshot guarantees that no change is lost
+	events, cancel, _ := p.store.memory().subscribe(0, false)
+	revision, err := p.publishSnapshot(ctx, producer)
+	if err != nil {
+		cancel()
+		return nil, func() {}, last, err
+	}
+	return events, cancel, revision, nil
+}
+
+// publishSnapshot sends all the served entries and returns the revision of the last change included
+func (p *KafkaPublisher) publishSnapshot(ctx context.Context, producer sarama.SyncProducer) (uint64, error) {
+	snapshot := p.store.memory().snapshot()
+	revision := snapshot.Provenance.Revision
+	err := p.send(ctx, producer, EventSnapshot, EventSnapshot,
+		snapshotMessage{Revision: revision, Type: EventSnapshot, Snapshot: snapshot})
+	return revision, err
+}
+
+// send publishes a message, the changes to the same entry have the same key so that they're delivered in order
+func (p *KafkaPublisher) send(ctx context.Context, producer sarama.SyncProducer, messageType string, key string,
+	message interface{}) error {
+	value, err := json.Marshal(message)
+	if err != nil {
+		logger.Errorw(ctx, "cannot-encode-kafka-message", log.Fields{"key": key, "err": err})
+		return err
+	}
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.config.KafkaTopic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	if err != nil {
+		kafkaMessagesTotal.WithLabelValues(messageType, kafkaFailure).Inc()
+		logger.Warnw(ctx, "cannot-publish-on-kafka", log.Fields{"topic": p.config.KafkaTopic, "key": key, "err": err})
+		return err
+	}
+	kafkaMessagesTotal.WithLabelValues(messageType, kafkaSuccess).Inc()
+	logger.Debugw(ctx, "published-on-kafka", log.Fields{"topic": p.config.KafkaTopic, "key": key, "type": messageType})
+	return nil
+}
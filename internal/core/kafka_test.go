@@ -0,0 +1,225 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bpEvent is an Event carrying a bandwidth profile
+type bpEvent struct {
+	Revision uint64
+	Type     string
+	Kind     string
+	ID       string
+	Entry    SadisBWPEntry
+	Owner    Owner
+}
+
+func nextMessage(t *testing.T, messages chan *sarama.ProducerMessage, value interface{}) *sarama.ProducerMessage {
+	select {
+	case msg := <-messages:
+		encoded, err := msg.Value.Encode()
+		assert.NilError(t, err)
+		assert.NilError(t, json.Unmarshal(encoded, value))
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for kafka message")
+	}
+	return nil
+}
+
+// newMockPublisher returns a publisher whose messages are delivered on the returned channel,
+// expected is the number of messages that have to be sent before the publisher is stopped
+func newMockPublisher(t *testing.T, store Storage, cf *utils.ConfigFlags, expected int) (*KafkaPublisher, chan *sarama.ProducerMessage) {
+	messages := make(chan *sarama.ProducerMessage, expected)
+	producer := mocks.NewSyncProducer(t, nil)
+	for i := 0; i < expected; i++ {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			messages <- msg
+			return nil
+		})
+	}
+
+	publisher := NewKafkaPublisher(store, cf)
+	publisher.newProducer = func() (sarama.SyncProducer, error) { return producer, nil }
+	return publisher, messages
+}
+
+func Test_KafkaPublisher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, owner)
+
+	cf := utils.NewConfigFlags()
+	cf.KafkaTopic = "sadis"
+	publisher, messages := newMockPublisher(t, store, cf, 3)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go publisher.Publish(ctx, &wg)
+
+	// all the entries are published at startup
+	var snapshot snapshotMessage
+	msg := nextMessage(t, messages, &snapshot)
+	assert.Equal(t, msg.Topic, "sadis")
+	assert.Equal(t, snapshot.Type, EventSnapshot)
+	assert.Equal(t, len(snapshot.Snapshot.BandwidthProfile.Entries), 1)
+
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 2000}, owner)
+	var event bpEvent
+	msg = nextMessage(t, messages, &event)
+	assert.Equal(t, msg.Key, sarama.StringEncoder("bp/Default"))
+	assert.Equal(t, event.Revision, snapshot.Revision+1)
+	assert.Equal(t, event.Type, EventUpdate)
+	assert.Equal(t, event.Entry.CIR, 2000)
+	assert.Equal(t, event.Owner, owner)
+
+	store.removeOwner(ctx, owner)
+	nextMessage(t, messages, &event)
+	assert.Equal(t, event.Type, EventDelete)
+
+	// the mock producer fails the test if the expected messages have not been sent when it's closed
+	cancel()
+	wg.Wait()
+}
+
+func Test_KafkaPublisher_resubscribe(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	store.events = newEventLog(2, 0)
+	for _, cir := range []int{1000, 2000, 3000} {
+		store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: cir}, Owner{Source: SourcePod, Name: "bbsim0"})
+	}
+
+	cf := utils.NewConfigFlags()
+	publisher, messages := newMockPublisher(t, store, cf, 1)
+	producer, _ := publisher.newProducer()
+	defer producer.Close()
+
+	// the changes after revision 1 are still available
+	events, cancel, last, err := publisher.subscribe(ctx, producer, 1)
+	assert.NilError(t, err)
+	defer cancel()
+	assert.Equal(t, last, uint64(1))
+	assert.Equal(t, nextEvent(t, events).Revision, uint64(2))
+
+	// the ones after revision 0 aren't, all the entries are published
+	_, cancel, last, err = publisher.subscribe(ctx, producer, 0)
+	assert.NilError(t, err)
+	defer cancel()
+	assert.Equal(t, last, uint64(3))
+	var snapshot snapshotMessage
+	nextMessage(t, messages, &snapshot)
+	assert.Equal(t, snapshot.Revision, uint64(3))
+	assert.Equal(t, snapshot.Snapshot.BandwidthProfile.Entries[0].CIR, 3000)
+}
+
+func Test_KafkaPublisher_sendFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Name: "bbsim0"}
+
+	// the first change can't be published, then the brokers are reachable again
+	messages := make(chan *sarama.ProducerMessage, 2)
+	deliver := func(msg *sarama.ProducerMessage) error {
+		messages <- msg
+		return nil
+	}
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(deliver)
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(deliver)
+
+	publisher := NewKafkaPublisher(store, utils.NewConfigFlags())
+	publisher.newProducer = func() (sarama.SyncProducer, error) { return producer, nil }
+	publisher.retryInterval = 10 * time.Millisecond
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go publisher.Publish(ctx, &wg)
+
+	var snapshot snapshotMessage
+	nextMessage(t, messages, &snapshot)
+
+	// the failed change is published again
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, owner)
+	var event bpEvent
+	nextMessage(t, messages, &event)
+	assert.Equal(t, event.Revision, snapshot.Revision+1)
+	assert.Equal(t, event.Entry.CIR, 1000)
+
+	cancel()
+	wg.Wait()
+}
+
+func Test_KafkaPublisher_broker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cf := utils.NewConfigFlags()
+
+	// an in-process broker that accepts all the messages
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(cf.KafkaTopic, 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t),
+	})
+	cf.KafkaAddresses = []string{broker.Addr()}
+
+	store := NewStore()
+	publisher := NewKafkaPublisher(store, cf)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go publisher.Publish(ctx, &wg)
+
+	produced := func() int {
+		count := 0
+		for _, rr := range broker.History() {
+			if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+				count++
+			}
+		}
+		return count
+	}
+
+	// the snapshot sent at startup
+	waitFor(t, func() bool { return produced() == 1 })
+
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, Owner{Source: SourcePod, Name: "bbsim0"})
+	waitFor(t, func() bool { return produced() == 2 })
+
+	cancel()
+	wg.Wait()
+}
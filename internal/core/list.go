@@ -0,0 +1,182 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// Page is a chunk of a listing, Continue is set if there are more entries
+// and has to be passed as the continue parameter to get the next page
+type Page struct {
+	Total    int           `json:"total"`
+	Continue string        `json:"continue,omitempty"`
+	Entries  []interface{} `json:"entries"`
+}
+
+// listIDs returns the IDs of the served entries of the given type, sorted
+func (s *Store) listIDs(kind string) []string {
+	ids := []string{}
+	for _, t := range s.tables() {
+		if t.kind != kind {
+			continue
+		}
+		t.resolved.Range(func(key, value interface{}) bool {
+			ids = append(ids, key.(string))
+			return true
+		})
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// countEntries returns the number of served entries of the given type
+func (s *Store) countEntries(kind string) int {
+	count := 0
+	for _, t := range s.tables() {
+		if t.kind == kind {
+			t.resolved.Range(func(key, value interface{}) bool {
+				count++
+				return true
+			})
+		}
+	}
+	return count
+}
+
+// union merges two sorted lists of IDs, IDs that are in both are only returned once
+func union(a []string, b []string) []string {
+	res := []string{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			res = append(res, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			res = append(res, b[j])
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// paginate returns the page of ids requested via the limit and continue parameters
+func paginate(r *http.Request, ids []string) ([]string, string, error) {
+	query := r.URL.Query()
+
+	limit := defaultPageLimit
+	if value := query.Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		limit = l
+	}
+	return pageOf(ids, limit, query.Get("continue"))
+}
+
+// pageOf returns up to limit ids, starting after the one the continue token points to.
+// The continue token is the last ID that was returned, so that pages are not affected
+// by entries that are added or removed in the meantime.
+func pageOf(ids []string, limit int, token string) ([]string, string, error) {
+	if limit < 1 || limit > maxPageLimit {
+		return nil, "", fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+
+	start := 0
+	if token != "" {
+		last, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid continue token")
+		}
+		start = sort.Search(len(ids), func(i int) bool { return ids[i] > string(last) })
+	}
+
+	end := start + limit
+	if end >= len(ids) {
+		return ids[start:], "", nil
+	}
+	return ids[start:end], base64.RawURLEncoding.EncodeToString([]byte(ids[end-1])), nil
+}
+
+// serveList writes the requested page of ids, get returns the entry to serve for an ID
+func (s Server) serveList(ctx context.Context, w http.ResponseWriter, r *http.Request, kind string, ids []string,
+	get func(id string) (interface{}, error)) {
+	page, next, err := paginate(r, ids)
+	if err != nil {
+		s.writeError(ctx, w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := Page{Total: len(ids), Continue: next, Entries: []interface{}{}}
+	for _, id := range page {
+		// the entry may have been removed after the IDs were listed
+		if entry, err := get(id); err == nil {
+			res.Entries = append(res.Entries, entry)
+		}
+	}
+
+	s.writeCacheable(w, r, res, true)
+	logger.Infow(ctx, "responded-to-list-request", log.Fields{"kind": kind, "total": res.Total, "entries": len(res.Entries)})
+}
+
+func (s Server) getSubscriber(ctx context.Context, id string) (interface{}, error) {
+	if olt, err := s.store.getOlt(ctx, id); err == nil {
+		return olt, nil
+	}
+	return s.store.getOnu(ctx, id)
+}
+
+func (s Server) serveOlts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.Debug(ctx, "received-olt-list-request")
+	s.serveList(ctx, w, r, EntryTypeOlt, s.store.listIDs(EntryTypeOlt), func(id string) (interface{}, error) {
+		return s.store.getOlt(r.Context(), id)
+	})
+}
+
+func (s Server) serveOnus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.Debug(ctx, "received-onu-list-request")
+	s.serveList(ctx, w, r, EntryTypeOnu, s.store.listIDs(EntryTypeOnu), func(id string) (interface{}, error) {
+		return s.store.getOnu(r.Context(), id)
+	})
+}
+
+func (s Server) serveBWPEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.Debug(ctx, "received-bandwidthprofile-list-request")
+	s.serveList(ctx, w, r, EntryTypeBp, s.store.listIDs(EntryTypeBp), func(id string) (interface{}, error) {
+		return s.store.getBp(r.Context(), id)
+	})
+}
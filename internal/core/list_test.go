@@ -0,0 +1,105 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type testPage struct {
+	Total    int                      `json:"total"`
+	Continue string                   `json:"continue"`
+	Entries  []map[string]interface{} `json:"entries"`
+}
+
+func getPage(t *testing.T, u string) (int, testPage) {
+	res, err := http.Get(u)
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	page := testPage{}
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	return res.StatusCode, page
+}
+
+func Test_serveList(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	owner := Owner{Source: SourcePod, Name: "bbsim0"}
+
+	set := entrySet{olts: []SadisOltEntry{{ID: "BBSM_OLT_0"}}, bps: []SadisBWPEntry{{ID: "Default"}}}
+	for i := 0; i < 5; i++ {
+		set.onus = append(set.onus, SadisOnuEntryV2{ID: fmt.Sprintf("BBSM_ONU_0-%d", i)})
+	}
+	store.replaceOwner(ctx, owner, set)
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	// walk the ONUs two at a time, removing one that has already been returned in the meantime
+	ids := []string{}
+	token := ""
+	for {
+		status, page := getPage(t, server.URL+"/onus?limit=2&continue="+url.QueryEscape(token))
+		assert.Equal(t, status, http.StatusOK)
+		for _, entry := range page.Entries {
+			ids = append(ids, entry["id"].(string))
+		}
+		if len(ids) == 2 {
+			assert.Equal(t, page.Total, 5)
+			set.onus = set.onus[1:]
+			store.replaceOwner(ctx, owner, set)
+		}
+		if page.Continue == "" {
+			break
+		}
+		token = page.Continue
+	}
+	assert.DeepEqual(t, ids, []string{"BBSM_ONU_0-0", "BBSM_ONU_0-1", "BBSM_ONU_0-2", "BBSM_ONU_0-3", "BBSM_ONU_0-4"})
+
+	status, page := getPage(t, server.URL+"/subscribers")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, page.Total, 5)
+	assert.Equal(t, page.Entries[0]["id"], "BBSM_OLT_0")
+	assert.Equal(t, page.Continue, "")
+
+	status, page = getPage(t, server.URL+"/olts")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, page.Total, 1)
+
+	status, page = getPage(t, server.URL+"/profiles")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, page.Entries[0]["id"], "Default")
+
+	status, _ = getPage(t, server.URL+"/onus?limit=0")
+	assert.Equal(t, status, http.StatusBadRequest)
+	status, _ = getPage(t, server.URL+"/onus?continue=%25")
+	assert.Equal(t, status, http.StatusBadRequest)
+}
+
+func Test_union(t *testing.T) {
+	assert.DeepEqual(t, union([]string{"a", "c", "d"}, []string{"b", "c", "e"}), []string{"a", "b", "c", "d", "e"})
+	assert.DeepEqual(t, union(nil, []string{"a"}), []string{"a"})
+	assert.DeepEqual(t, union([]string{"a"}, nil), []string{"a"})
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const metricsNamespace = "bbsim_sadis_server"
+
+// outcomes of the lookups
+const (
+	lookupOltHit   = "olt-hit"
+	lookupOnuHit   = "onu-hit"
+	lookupBpHit    = "bp-hit"
+	lookupNotFound = "not-found"
+)
+
+const (
+	kafkaSuccess = "success"
+	kafkaFailure = "failure"
+)
+
+var (
+	lookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "lookups_total",
+		Help:      "Number of lookups of SADIS entries by ID, by endpoint and outcome",
+	}, []string{"endpoint", "outcome"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests, by route, method and status code",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	fetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of the requests to fetch the SADIS configuration from BBSim, by source",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+
+	fetchFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "fetch_failures_total",
+		Help:      "Number of failed requests to fetch the SADIS configuration from BBSim, by source",
+	}, []string{"source"})
+
+	fetchLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "fetch_last_success_timestamp_seconds",
+		Help:      "Time of the last successful fetch of the SADIS configuration from BBSim, by source",
+	}, []string{"source"})
+
+	watchRestartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "pod_watch_restarts_total",
+		Help:      "Number of times the pod watch has been interrupted and re-established",
+	}, []string{"namespace", "label_selector"})
+
+	kafkaMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "kafka_messages_total",
+		Help:      "Number of messages published on Kafka, by type and result",
+	}, []string{"type", "result"})
+
+	validationFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "validation_failures_total",
+		Help:      "Number of rule violations detected in the published entries, by rule and action",
+	}, []string{"rule", "action"})
+
+	entriesDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "entries"),
+		"Number of entries served, by type", []string{"type"}, nil)
+)
+
+// storeCollector reports the number of entries in the store when metrics are scraped
+type storeCollector struct {
+	store Storage
+}
+
+func (c storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- entriesDesc
+}
+
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, kind := range []string{EntryTypeOlt, EntryTypeOnu, EntryTypeBp} {
+		ch <- prometheus.MustNewConstMetric(entriesDesc, prometheus.GaugeValue, float64(c.store.countEntries(kind)), kind)
+	}
+}
+
+// newMetricsRegistry returns the registry of the metrics exposed on /metrics, a new one is created
+// for every server (instead of using the default one) so that the store collector can be registered more than once
+func newMetricsRegistry(store Storage) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		lookupsTotal, requestDuration, fetchDuration, fetchFailuresTotal, fetchLastSuccess,
+		watchRestartsTotal, kafkaMessagesTotal, validationFailuresTotal, storeCollector{store: store})
+	return registry
+}
+
+// forgetSourceMetrics drops the metrics of a source that is not fetched anymore
+func forgetSourceMetrics(owner Owner) {
+	fetchDuration.DeleteLabelValues(owner.String())
+	fetchFailuresTotal.DeleteLabelValues(owner.String())
+	fetchLastSuccess.DeleteLabelValues(owner.String())
+}
+
+// statusRecorder keeps track of the status code of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush is needed by the handlers that stream the response
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// instrument is a middleware that measures the duration of the requests,
+// the route template is used as label (eg: /subscribers/{ID}) so that the number of series is bounded
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Observe(time.Since(start).Seconds())
+	})
+}
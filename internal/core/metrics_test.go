@@ -0,0 +1,69 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Metrics(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	store.replaceOwner(ctx, Owner{Source: SourcePod, Name: "bbsim0"}, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0"}},
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1"}, {ID: "BBSM_ONU_0-2"}},
+	})
+
+	// the counters are shared with the other tests
+	lookupsTotal.Reset()
+	requestDuration.Reset()
+
+	server := httptest.NewServer(NewServer(store, nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	for _, path := range []string{"/subscribers/BBSM_OLT_0", "/subscribers/BBSM_ONU_0-1", "/subscribers/unknown", "/profiles/Default"} {
+		res, err := http.Get(server.URL + path)
+		assert.NilError(t, err)
+		res.Body.Close()
+	}
+
+	res, err := http.Get(server.URL + "/metrics")
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NilError(t, err)
+
+	for _, line := range []string{
+		`bbsim_sadis_server_entries{type="olt"} 1`,
+		`bbsim_sadis_server_entries{type="onu"} 2`,
+		`bbsim_sadis_server_entries{type="bp"} 0`,
+		`bbsim_sadis_server_lookups_total{endpoint="subscribers",outcome="olt-hit"} 1`,
+		`bbsim_sadis_server_lookups_total{endpoint="subscribers",outcome="onu-hit"} 1`,
+		`bbsim_sadis_server_lookups_total{endpoint="subscribers",outcome="not-found"} 1`,
+		`bbsim_sadis_server_lookups_total{endpoint="profiles",outcome="not-found"} 1`,
+		`bbsim_sadis_server_http_request_duration_seconds_count{code="404",method="GET",route="/subscribers/{ID}"} 1`,
+	} {
+		assert.Assert(t, strings.Contains(string(body), line), line)
+	}
+}
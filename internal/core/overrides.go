@@ -0,0 +1,205 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"io/ioutil"
+	"net/http"
+)
+
+// overrideOwner publishes the entries set via the admin API, they take precedence
+// over the discovered ones so that re-fetching BBSim does not clobber them
+var overrideOwner = Owner{Source: SourceOverride, Name: "admin"}
+
+// putEntry overrides an OLT (if hardwareIdentifier is set) or an ONU (if uniTagList is set)
+func (s Server) putEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	var entry SadisEntry
+	if err := decodeBody(r, &entry); err != nil {
+		s.writeError(ctx, w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if entry.ID != "" && entry.ID != id {
+		s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("The entry ID must be %s.", id))
+		return
+	}
+	entry.ID = id
+
+	config := SadisConfig{}
+	config.Sadis.Entries = []*SadisEntry{&entry}
+	set := newEntrySet(ctx, config)
+	switch {
+	case len(set.olts) == 1:
+		if !s.checkOverride(ctx, w, set.olts[0]) {
+			return
+		}
+		s.store.addOlt(ctx, set.olts[0], overrideOwner)
+		s.writeEntry(ctx, w, set.olts[0])
+	case len(set.onus) == 1:
+		if !s.checkOverride(ctx, w, set.onus[0]) {
+			return
+		}
+		s.store.addOnu(ctx, set.onus[0], overrideOwner)
+		s.writeEntry(ctx, w, set.onus[0])
+	default:
+		s.writeError(ctx, w, http.StatusBadRequest, "Either hardwareIdentifier or uniTagList must be set.")
+	}
+}
+
+// patchEntry overrides the fields in the request body of the served OLT or ONU,
+// lists (eg: uniTagList) are replaced as a whole
+func (s Server) patchEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	if olt, err := s.store.getOlt(ctx, id); err == nil {
+		if err := decodeBody(r, olt); err != nil || olt.ID != id {
+			s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("Invalid patch for OLT %s.", id))
+			return
+		}
+		if !s.checkOverride(ctx, w, *olt) {
+			return
+		}
+		s.store.addOlt(ctx, *olt, overrideOwner)
+		s.writeEntry(ctx, w, olt)
+		return
+	}
+
+	if onu, err := s.store.getOnu(ctx, id); err == nil {
+		if err := decodeOnuPatch(r, onu); err != nil || onu.ID != id {
+			s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("Invalid patch for ONU %s.", id))
+			return
+		}
+		if !s.checkOverride(ctx, w, *onu) {
+			return
+		}
+		s.store.addOnu(ctx, *onu, overrideOwner)
+		s.writeEntry(ctx, w, onu)
+		return
+	}
+
+	s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("Entry with ID %s not found.", id))
+}
+
+// deleteEntry removes the override for an OLT or ONU, the discovered entry (if any) is served again
+func (s Server) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	removedOlt := s.store.removeEntry(ctx, EntryTypeOlt, id, overrideOwner)
+	removedOnu := s.store.removeEntry(ctx, EntryTypeOnu, id, overrideOwner)
+	if !removedOlt && !removedOnu {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("Override for entry with ID %s not found.", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s Server) putBWPEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	var bp SadisBWPEntry
+	if err := decodeBody(r, &bp); err != nil {
+		s.writeError(ctx, w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if bp.ID != "" && bp.ID != id {
+		s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("The bandwidth profile ID must be %s.", id))
+		return
+	}
+	bp.ID = id
+	if !s.checkOverride(ctx, w, bp) {
+		return
+	}
+
+	s.store.addBp(ctx, bp, overrideOwner)
+	s.writeEntry(ctx, w, bp)
+}
+
+func (s Server) patchBWPEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	bp, err := s.store.getBp(ctx, id)
+	if err != nil {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("BandwidthProfile with ID %s not found.", id))
+		return
+	}
+	if err := decodeBody(r, bp); err != nil || bp.ID != id {
+		s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("Invalid patch for bandwidth profile %s.", id))
+		return
+	}
+	if !s.checkOverride(ctx, w, *bp) {
+		return
+	}
+
+	s.store.addBp(ctx, *bp, overrideOwner)
+	s.writeEntry(ctx, w, bp)
+}
+
+func (s Server) deleteBWPEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["ID"]
+
+	if !s.store.removeEntry(ctx, EntryTypeBp, id, overrideOwner) {
+		s.writeError(ctx, w, http.StatusNotFound, fmt.Sprintf("Override for bandwidth profile with ID %s not found.", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s Server) writeEntry(ctx context.Context, w http.ResponseWriter, entry interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entry)
+	logger.Infow(ctx, "stored-override", log.Fields{"entry": entry})
+}
+
+// decodeBody unmarshals the request body on top of the values already in v
+func decodeBody(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// decodeOnuPatch applies the patch in the request body to onu. The uniTagList is decoded in a new slice,
+// as decoding into the existing one would keep the fields of the tags missing in the patch and would
+// modify the entry published by the owner (eg: a BBSim pod), that shares it with the served one.
+func decodeOnuPatch(r *http.Request, onu *SadisOnuEntryV2) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["uniTagList"]; ok {
+		onu.UniTagList = nil
+	}
+	return json.Unmarshal(body, onu)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func adminRequest(t *testing.T, method string, url string, token string, body string) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NilError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	return res.StatusCode
+}
+
+func Test_Overrides(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	discovered := entrySet{
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", CircuitID: "circuit", UniTagList: []SadisUniTag{{PonCTag: 10}}}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 1000, CBS: 100}},
+	}
+	store.replaceOwner(ctx, pod, discovered)
+
+	cf := utils.NewConfigFlags()
+	cf.AdminToken = "secret"
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+	server := httptest.NewServer(NewServer(store, nil, auth, cf).router())
+	defer server.Close()
+
+	// writes require the admin token
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/profiles/Default", "", `{"cir": 2000}`), http.StatusUnauthorized)
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/profiles/Default", "wrong", `{"cir": 2000}`), http.StatusUnauthorized)
+
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/profiles/Default", "secret", `{"cir": 2000}`), http.StatusOK)
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/subscribers/BBSM_ONU_0-1", "secret",
+		`{"uniTagList": [{"ponCTag": 20}]}`), http.StatusOK)
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/subscribers/LAB_OLT_0", "secret",
+		`{"hardwareIdentifier": "00:00:00:00:00:01"}`), http.StatusOK)
+
+	// re-fetching BBSim does not clobber the overrides
+	store.replaceOwner(ctx, pod, discovered)
+
+	bp, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 2000)
+	assert.Equal(t, bp.CBS, 100)
+	onu, err := store.getOnu(ctx, "BBSM_ONU_0-1")
+	assert.NilError(t, err)
+	assert.Equal(t, onu.CircuitID, "circuit")
+	assert.Equal(t, onu.UniTagList[0].PonCTag, 20)
+	_, err = store.getOlt(ctx, "LAB_OLT_0")
+	assert.NilError(t, err)
+
+	// once the override is deleted the discovered entry is served again
+	assert.Equal(t, adminRequest(t, http.MethodDelete, server.URL+"/profiles/Default", "secret", ""), http.StatusNoContent)
+	bp, err = store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 1000)
+	assert.Equal(t, adminRequest(t, http.MethodDelete, server.URL+"/profiles/Default", "secret", ""), http.StatusNotFound)
+
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/subscribers/unknown", "secret", `{}`), http.StatusNotFound)
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/subscribers/LAB_ONU_0", "secret", `{}`), http.StatusBadRequest)
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/profiles/Default", "secret", `{"id": "Other"}`), http.StatusBadRequest)
+}
+
+func Test_Overrides_patchDoesNotModifyDiscovered(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	store.replaceOwner(ctx, pod, entrySet{
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10, PonSTag: 20}}}},
+	})
+
+	cf := utils.NewConfigFlags()
+	cf.AdminToken = "secret"
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+	server := httptest.NewServer(NewServer(store, nil, auth, cf).router())
+	defer server.Close()
+
+	// the list is replaced as a whole, the fields missing in the patch are not kept
+	assert.Equal(t, adminRequest(t, http.MethodPatch, server.URL+"/subscribers/BBSM_ONU_0-1", "secret",
+		`{"uniTagList": [{"ponCTag": 999}]}`), http.StatusOK)
+	onu, err := store.getOnu(ctx, "BBSM_ONU_0-1")
+	assert.NilError(t, err)
+	assert.Equal(t, onu.UniTagList[0].PonCTag, 999)
+	assert.Equal(t, onu.UniTagList[0].PonSTag, 0)
+
+	// once the override is deleted the discovered entry is served as it was published
+	assert.Equal(t, adminRequest(t, http.MethodDelete, server.URL+"/subscribers/BBSM_ONU_0-1", "secret", ""), http.StatusNoContent)
+	onu, err = store.getOnu(ctx, "BBSM_ONU_0-1")
+	assert.NilError(t, err)
+	assert.Equal(t, onu.UniTagList[0].PonCTag, 10)
+	assert.Equal(t, onu.UniTagList[0].PonSTag, 20)
+}
+
+func Test_OverridesDisabled(t *testing.T) {
+	server := httptest.NewServer(NewServer(NewStore(), nil, nil, utils.NewConfigFlags()).router())
+	defer server.Close()
+
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/profiles/Default", "", `{"cir": 1000}`), http.StatusForbidden)
+	// reads are not affected
+	assert.Equal(t, adminRequest(t, http.MethodGet, server.URL+"/profiles/Default", "", ""), http.StatusNotFound)
+}
@@ -18,90 +18,318 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
 	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type Server struct {
-	store *Store
+	store  Storage
+	health *Health
+	auth   *Auth
+	config *utils.ConfigFlags
+	// stopping is closed when the server is shut down, to end the streaming responses
+	stopping chan struct{}
 }
 
-func NewServer(store *Store) *Server {
+// NewServer creates the SADIS server, if auth is nil the API can be read by anyone and the admin API is disabled
+func NewServer(store Storage, health *Health, auth *Auth, cf *utils.ConfigFlags) *Server {
+	if auth == nil {
+		auth = &Auth{anonymousRole: RoleRead}
+	}
 	return &Server{
-		store: store,
+		store:    store,
+		health:   health,
+		auth:     auth,
+		config:   cf,
+		stopping: make(chan struct{}),
 	}
 }
 
-func (s *Server) StartSadisServer(wg *sync.WaitGroup) {
+// StartSadisServer serves the SADIS API until ctx is done
+func (s *Server) StartSadisServer(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	ctx := context.Background()
 
-	addr := "0.0.0.0:8080"
+	addr := net.JoinHostPort(s.config.ListenAddress, strconv.Itoa(s.config.ListenPort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatalw(ctx, "cannot-listen", log.Fields{"addr": addr, "err": err})
+	}
 
+	var tlsConfig *tls.Config
+	if s.config.TLSCert != "" {
+		tlsConfig, err = newTLSConfig(s.config)
+		if err != nil {
+			logger.Fatalw(ctx, "cannot-load-tls-certificates", log.Fields{"cert": s.config.TLSCert, "key": s.config.TLSKey,
+				"clientCa": s.config.TLSClientCA, "err": err})
+		}
+	}
+
+	logger.Infow(ctx, "starting-sadis-server", log.Fields{"addr": addr, "tls": tlsConfig != nil,
+		"mtls": s.config.TLSClientCA != ""})
+	if err := s.serve(ctx, listener, tlsConfig); err != nil {
+		logger.Fatal(ctx, err)
+	}
+}
+
+// serve handles the requests on listener until ctx is done, then stops accepting new connections
+// and waits up to the shutdown timeout for the requests in progress to complete
+func (s *Server) serve(ctx context.Context, listener net.Listener, tlsConfig *tls.Config) error {
+	server := &http.Server{Handler: s.router(), TLSConfig: tlsConfig}
+	// Shutdown waits for all the handlers to return, so the streams are ended as soon as it starts
+	server.RegisterOnShutdown(func() { close(s.stopping) })
+
+	if tlsConfig != nil {
+		// the certificates are picked per connection by GetConfigForClient, ServeTLS refuses
+		// a config with neither Certificates nor GetCertificate, so the listener is wrapped instead
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Infow(ctx, "stopping-sadis-server", log.Fields{"timeout": s.config.ShutdownTimeout.String()})
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warnw(ctx, "closing-requests-in-progress", log.Fields{"err": err})
+		return server.Close()
+	}
+	logger.Info(ctx, "sadis-server-stopped")
+	return nil
+}
+
+func (s *Server) router() *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
-	router.HandleFunc("/subscribers/{ID}", s.serveEntry)
-	router.HandleFunc("/profiles/{ID}", s.serveBWPEntry)
+	router.Use(instrument)
+
+	// probes and metrics don't require authentication
+	router.Handle("/metrics", promhttp.HandlerFor(newMetricsRegistry(s.store), promhttp.HandlerOpts{}))
+	if s.health != nil {
+		router.HandleFunc("/healthz", s.serveHealthz)
+		router.HandleFunc("/readyz", s.serveReadyz)
+	}
+
+	read := func(path string, handler http.HandlerFunc) *mux.Route {
+		return router.HandleFunc(path, s.authorize(RoleRead, handler))
+	}
+	admin := func(path string, handler http.HandlerFunc) *mux.Route {
+		return router.HandleFunc(path, s.authorize(RoleAdmin, handler))
+	}
+
+	// the admin API maintains overrides that take precedence over the discovered entries
+	admin("/subscribers/{ID}", s.putEntry).Methods(http.MethodPut)
+	admin("/subscribers/{ID}", s.patchEntry).Methods(http.MethodPatch)
+	admin("/subscribers/{ID}", s.deleteEntry).Methods(http.MethodDelete)
+	admin("/profiles/{ID}", s.putBWPEntry).Methods(http.MethodPut)
+	admin("/profiles/{ID}", s.patchBWPEntry).Methods(http.MethodPatch)
+	admin("/profiles/{ID}", s.deleteBWPEntry).Methods(http.MethodDelete)
+	admin("/snapshot", s.importSnapshot).Methods(http.MethodPost)
 
-	logger.Fatal(ctx, http.ListenAndServe(addr, router))
+	read("/subscribers", s.serveEntries)
+	read("/subscribers/{ID}", s.serveEntry)
+	read("/olts", s.serveOlts)
+	read("/olts/{ID}/onus", s.serveOltOnus)
+	read("/onus", s.serveOnus)
+	read("/onus/{ID}/olt", s.serveOnuOlt)
+	read("/profiles", s.serveBWPEntries)
+	read("/profiles/{ID}", s.serveBWPEntry)
+	read("/conflicts", s.serveConflicts)
+	read("/validation", s.serveValidation)
+	read("/snapshot", s.serveSnapshot).Methods(http.MethodGet)
+	read("/events", s.serveEvents).Methods(http.MethodGet)
+	return router
 }
 
 func (s Server) serveEntry(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["ID"]
 
-	ctx := context.TODO()
+	ctx := r.Context()
 	logger.Debugw(ctx, "received-sadis-entry-request", log.Fields{"id": id})
 
-	w.Header().Set("Content-Type", "application/json")
-
 	if olt, err := s.store.getOlt(r.Context(), id); err == nil {
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(olt)
+		s.writeCacheable(w, r, olt, false)
+		lookupsTotal.WithLabelValues("subscribers", lookupOltHit).Inc()
 		logger.Infow(ctx, "responded-to-sadis-olt-entry-request", log.Fields{"id": id})
 		return
 	}
 
 	if onu, err := s.store.getOnu(r.Context(), id); err == nil {
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(onu)
+		s.writeCacheable(w, r, onu, false)
+		lookupsTotal.WithLabelValues("subscribers", lookupOnuHit).Inc()
 		logger.Infow(ctx, "responded-to-sadis-onu-entry-request", log.Fields{"id": id})
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
 	msg := make(map[string]interface{})
 	msg["statusCode"] = http.StatusNotFound
 	msg["message"] = fmt.Sprintf("Entry with ID %s not found.", id)
 	_ = json.NewEncoder(w).Encode(msg)
 
+	lookupsTotal.WithLabelValues("subscribers", lookupNotFound).Inc()
 	logger.Warnw(ctx, "sadis-entry-not-found", log.Fields{"id": id})
 }
 
+// serveEntries lists the OLTs and ONUs, if any of the IndexedFields is in the query
+// only the entries matching all of them are returned (eg: /subscribers?circuitId=...)
+func (s Server) serveEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filters := map[string]string{}
+	for _, field := range IndexedFields {
+		if _, ok := query[field]; ok {
+			filters[field] = query.Get(field)
+		}
+	}
+	logger.Debugw(ctx, "received-sadis-entries-request", log.Fields{"filters": filters})
+
+	s.serveList(ctx, w, r, "subscriber", matching(s.store.memory(), filters), func(id string) (interface{}, error) {
+		return s.getSubscriber(r.Context(), id)
+	})
+}
+
+// matching returns the IDs (sorted) of the OLTs and ONUs where all the indexed fields
+// have the values in filters, or all of them if there are no filters
+func matching(store *Store, filters map[string]string) []string {
+	if len(filters) == 0 {
+		return union(store.listIDs(EntryTypeOlt), store.listIDs(EntryTypeOnu))
+	}
+	var ids []string
+	first := true
+	for _, field := range IndexedFields {
+		if value, ok := filters[field]; ok {
+			ids = intersect(ids, store.lookup(field, value), first)
+			first = false
+		}
+	}
+	return ids
+}
+
+// intersect returns the IDs that are in both the sorted lists, or b if first is true
+func intersect(a []string, b []string, first bool) []string {
+	if first {
+		return b
+	}
+	res := []string{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return res
+}
+
 func (s Server) serveBWPEntry(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["ID"]
 
-	ctx := context.TODO()
+	ctx := r.Context()
 	logger.Debugw(ctx, "received-sadis-bandwidthprofile-request", log.Fields{"id": id})
 
-	w.Header().Set("Content-Type", "application/json")
-
 	if bp, err := s.store.getBp(r.Context(), id); err == nil {
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(bp)
+		s.writeCacheable(w, r, bp, false)
+		lookupsTotal.WithLabelValues("profiles", lookupBpHit).Inc()
 		logger.Infow(ctx, "responded-to-sadis-bandwidthprofile-request", log.Fields{"id": id})
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
 	msg := make(map[string]interface{})
 	msg["statusCode"] = http.StatusNotFound
 	msg["message"] = fmt.Sprintf("BandwidthProfile with ID %s not found.", id)
 	_ = json.NewEncoder(w).Encode(msg)
 
+	lookupsTotal.WithLabelValues("profiles", lookupNotFound).Inc()
 	logger.Warnw(ctx, "sadis-bandwidthprofile-not-found", log.Fields{"id": id})
 }
+
+func (s Server) serveConflicts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.Debug(ctx, "received-conflicts-request")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(s.store.memory().listConflicts())
+}
+
+func (s Server) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.Debug(ctx, "received-snapshot-request")
+
+	snapshot := s.store.memory().snapshot()
+
+	// the creation time differs at every request, the ETag only identifies the content
+	version := snapshot
+	version.Provenance.CreatedAt = time.Time{}
+
+	s.writeCacheableAs(w, r, snapshot, version, true)
+	logger.Infow(ctx, "responded-to-snapshot-request", log.Fields{"entries": len(snapshot.Sadis.Entries),
+		"bandwidthProfiles": len(snapshot.BandwidthProfile.Entries)})
+}
+
+func (s Server) importSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = SnapshotMerge
+	}
+	logger.Debugw(ctx, "received-snapshot-import-request", log.Fields{"mode": mode})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var snapshot Snapshot
+	err := json.NewDecoder(r.Body).Decode(&snapshot)
+	if err == nil {
+		err = importSnapshot(r.Context(), s.store, snapshot, mode)
+	}
+	if err != nil {
+		s.writeError(ctx, w, http.StatusBadRequest, fmt.Sprintf("Cannot import snapshot: %s", err.Error()))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	msg := make(map[string]interface{})
+	msg["statusCode"] = http.StatusOK
+	msg["message"] = fmt.Sprintf("Imported %d entries and %d bandwidth profiles.",
+		len(snapshot.Sadis.Entries), len(snapshot.BandwidthProfile.Entries))
+	_ = json.NewEncoder(w).Encode(msg)
+}
+
+// writeError replies with a JSON document in the same format used for the entries that are not found
+func (s Server) writeError(ctx context.Context, w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	msg := make(map[string]interface{})
+	msg["statusCode"] = status
+	msg["message"] = message
+	_ = json.NewEncoder(w).Encode(msg)
+	logger.Warnw(ctx, "request-failed", log.Fields{"statusCode": status, "message": message})
+}
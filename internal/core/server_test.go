@@ -0,0 +1,106 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// slowStore blocks the bandwidth profile lookups until released
+type slowStore struct {
+	*Store
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStore) getBp(ctx context.Context, id string) (*SadisBWPEntry, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return s.Store.getBp(ctx, id)
+}
+
+func startTestServer(t *testing.T, timeout time.Duration) (*slowStore, string, context.CancelFunc, chan error) {
+	store := &slowStore{Store: NewStore(), started: make(chan struct{}, 1), release: make(chan struct{})}
+	store.addBp(context.Background(), SadisBWPEntry{ID: "Default", CIR: 1000}, Owner{Source: SourcePod, Name: "bbsim0"})
+
+	cf := utils.NewConfigFlags()
+	cf.ShutdownTimeout = timeout
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- NewServer(store, nil, nil, cf).serve(ctx, listener, nil) }()
+
+	return store, "http://" + listener.Addr().String(), cancel, stopped
+}
+
+func Test_Server_shutdownDrainsRequests(t *testing.T) {
+	store, url, cancel, stopped := startTestServer(t, 5*time.Second)
+
+	responses := make(chan int, 1)
+	go func() {
+		res, err := http.Get(url + "/profiles/Default")
+		assert.NilError(t, err)
+		res.Body.Close()
+		responses <- res.StatusCode
+	}()
+
+	<-store.started
+	cancel()
+
+	// the server waits for the request in progress, but doesn't accept new connections
+	select {
+	case <-stopped:
+		t.Fatal("server stopped with a request in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+	_, err := http.Get(url + "/profiles/Default")
+	assert.Assert(t, err != nil)
+
+	close(store.release)
+	assert.Equal(t, <-responses, http.StatusOK)
+	assert.NilError(t, <-stopped)
+}
+
+func Test_Server_shutdownTimeout(t *testing.T) {
+	store, url, cancel, stopped := startTestServer(t, 50*time.Millisecond)
+	defer close(store.release)
+
+	errs := make(chan error, 1)
+	go func() {
+		res, err := http.Get(url + "/profiles/Default")
+		if err == nil {
+			res.Body.Close()
+		}
+		errs <- err
+	}()
+
+	<-store.started
+	cancel()
+
+	// the connections are closed once the timeout expires
+	assert.NilError(t, <-stopped)
+	assert.Assert(t, <-errs != nil)
+}
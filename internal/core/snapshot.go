@@ -0,0 +1,197 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotMerge adds the snapshot entries to the ones imported before
+	SnapshotMerge = "merge"
+	// SnapshotReplace removes the entries imported before loading the snapshot,
+	// the ones discovered from BBSim, loaded from files or set via the admin API are kept
+	SnapshotReplace = "replace"
+)
+
+// snapshotOwner publishes the entries of an imported snapshot,
+// importing another snapshot replaces them
+var snapshotOwner = Owner{Source: SourceSnapshot, Name: "import"}
+
+// Snapshot contains all the entries served to ONOS. It is a SadisConfig document
+// (so it can also be loaded from -sadis_dir) with the provenance of every entry.
+type Snapshot struct {
+	SadisConfig
+	Provenance SnapshotProvenance `json:"provenance"`
+}
+
+type SnapshotProvenance struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Host      string    `json:"host"`
+	// Revision is the one of the last change included in the snapshot, see /events
+	Revision uint64            `json:"revision"`
+	Entries  []EntryProvenance `json:"entries"`
+}
+
+// EntryProvenance lists the owners that published the served value of an entry
+type EntryProvenance struct {
+	Type   string  `json:"type"`
+	ID     string  `json:"id"`
+	Owners []Owner `json:"owners"`
+}
+
+// snapshot returns the entries that are currently served, sorted by ID
+func (s *Store) snapshot() Snapshot {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	snapshot := Snapshot{}
+	snapshot.Provenance.CreatedAt = time.Now().UTC()
+	snapshot.Provenance.Host = utils.GetHostName()
+	snapshot.Provenance.Revision = s.events.current()
+	snapshot.Provenance.Entries = []EntryProvenance{}
+
+	for _, t := range s.tables() {
+		ids := []string{}
+		t.resolved.Range(func(key, value interface{}) bool {
+			ids = append(ids, key.(string))
+			return true
+		})
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			value, ok := t.resolved.Load(id)
+			if !ok {
+				continue
+			}
+			switch entry := value.(type) {
+			case SadisOltEntry:
+				snapshot.Sadis.Entries = append(snapshot.Sadis.Entries, &SadisEntry{
+					ID:                 entry.ID,
+					HardwareIdentifier: entry.HardwareIdentifier,
+					IPAddress:          entry.IPAddress,
+					NasID:              entry.NasID,
+					UplinkPort:         entry.UplinkPort,
+					NniDhcpTrapVid:     entry.NniDhcpTrapVid,
+				})
+			case SadisOnuEntryV2:
+				snapshot.Sadis.Entries = append(snapshot.Sadis.Entries, &SadisEntry{
+					ID:         entry.ID,
+					NasPortID:  entry.NasPortID,
+					CircuitID:  entry.CircuitID,
+					RemoteID:   entry.RemoteID,
+					UniTagList: entry.UniTagList,
+				})
+			case SadisBWPEntry:
+				bp := entry
+				snapshot.BandwidthProfile.Entries = append(snapshot.BandwidthProfile.Entries, &bp)
+			}
+
+			// the same value can be published by more owners (eg: a bandwidth profile), all of them are listed
+			owners := []Owner{}
+			candidates := t.owners.candidates(id)
+			for _, owner := range sortedOwners(candidates) {
+				if reflect.DeepEqual(candidates[owner].value, value) {
+					owners = append(owners, owner)
+				}
+			}
+			snapshot.Provenance.Entries = append(snapshot.Provenance.Entries, EntryProvenance{Type: t.kind, ID: id, Owners: owners})
+		}
+	}
+	return snapshot
+}
+
+// importSnapshot loads the snapshot entries in the store, with SnapshotReplace
+// the entries of the snapshots imported before are removed first
+func importSnapshot(ctx context.Context, store Storage, snapshot Snapshot, mode string) error {
+	if mode != SnapshotMerge && mode != SnapshotReplace {
+		return fmt.Errorf("invalid-snapshot-mode-%s", mode)
+	}
+
+	set := newEntrySet(ctx, snapshot.SadisConfig)
+
+	if mode == SnapshotReplace {
+		store.replaceOwner(ctx, snapshotOwner, set)
+	} else {
+		for _, olt := range set.olts {
+			store.addOlt(ctx, olt, snapshotOwner)
+		}
+		for _, onu := range set.onus {
+			store.addOnu(ctx, onu, snapshotOwner)
+		}
+		for _, bp := range set.bps {
+			store.addBp(ctx, bp, snapshotOwner)
+		}
+	}
+
+	logger.Infow(ctx, "imported-snapshot", log.Fields{"mode": mode, "host": snapshot.Provenance.Host,
+		"createdAt": snapshot.Provenance.CreatedAt, "entries": len(snapshot.Sadis.Entries),
+		"bandwidthProfiles": len(snapshot.BandwidthProfile.Entries)})
+	return nil
+}
+
+// ExportSnapshot downloads the snapshot of the store from the server at serverURL (eg: http://localhost:8080),
+// token is the bearer token sent to the server, if any
+func ExportSnapshot(ctx context.Context, serverURL string, token string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(serverURL, "/")+"/snapshot", nil)
+	if err != nil {
+		return err
+	}
+	return doSnapshotRequest(req, token, w)
+}
+
+// ImportSnapshot uploads a snapshot to the server at serverURL, mode is either SnapshotMerge or SnapshotReplace.
+// Importing a snapshot requires the admin role.
+func ImportSnapshot(ctx context.Context, serverURL string, token string, r io.Reader, mode string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/snapshot?mode=%s", strings.TrimSuffix(serverURL, "/"), mode), r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doSnapshotRequest(req, token, io.Discard)
+}
+
+func doSnapshotRequest(req *http.Request, token string, w io.Writer) error {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg := map[string]interface{}{}
+		_ = json.NewDecoder(res.Body).Decode(&msg)
+		return fmt.Errorf("unexpected-status-code-%d: %v", res.StatusCode, msg["message"])
+	}
+
+	_, err = io.Copy(w, res.Body)
+	return err
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Snapshot(t *testing.T) {
+	ctx := context.TODO()
+
+	pod0 := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	pod1 := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim1", UID: "uid-1"}
+
+	source := NewStore()
+	source.replaceOwner(ctx, pod0, entrySet{
+		olts: []SadisOltEntry{{ID: "BBSM_OLT_0", HardwareIdentifier: "00:00:00:00:00:01"}},
+		onus: []SadisOnuEntryV2{{ID: "BBSM_ONU_0-1", UniTagList: []SadisUniTag{{PonCTag: 10}}}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 1000}},
+	})
+	source.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, pod1)
+
+	sourceServer := httptest.NewServer(NewServer(source, nil, nil, utils.NewConfigFlags()).router())
+	defer sourceServer.Close()
+
+	buf := &bytes.Buffer{}
+	assert.NilError(t, ExportSnapshot(ctx, sourceServer.URL, "", buf))
+
+	// importing a snapshot requires the admin role
+	cf := utils.NewConfigFlags()
+	cf.AdminToken = "secret"
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+
+	var snapshot Snapshot
+	assert.NilError(t, json.Unmarshal(buf.Bytes(), &snapshot))
+	assert.Equal(t, len(snapshot.Sadis.Entries), 2)
+	assert.Equal(t, len(snapshot.BandwidthProfile.Entries), 1)
+	assert.DeepEqual(t, snapshot.Provenance.Entries, []EntryProvenance{
+		{Type: EntryTypeOlt, ID: "BBSM_OLT_0", Owners: []Owner{pod0}},
+		{Type: EntryTypeOnu, ID: "BBSM_ONU_0-1", Owners: []Owner{pod0}},
+		{Type: EntryTypeBp, ID: "Default", Owners: []Owner{pod0, pod1}},
+	})
+
+	file := Owner{Source: SourceFile, Name: "/etc/sadis/lab.json"}
+	newTarget := func() *Store {
+		target := NewStore()
+		target.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_1"}, pod1)
+		target.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 2000}, pod1)
+		target.addBp(ctx, SadisBWPEntry{ID: "Lab", CIR: 3000}, file)
+		target.addBp(ctx, SadisBWPEntry{ID: "Admin", CIR: 4000}, overrideOwner)
+		// imported before
+		target.addBp(ctx, SadisBWPEntry{ID: "Imported", CIR: 5000}, snapshotOwner)
+		return target
+	}
+
+	tests := []struct {
+		mode     string
+		imported bool
+	}{
+		{SnapshotMerge, true},
+		{SnapshotReplace, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			target := newTarget()
+			server := httptest.NewServer(NewServer(target, nil, auth, cf).router())
+			defer server.Close()
+
+			assert.NilError(t, ImportSnapshot(ctx, server.URL, "secret", bytes.NewReader(buf.Bytes()), tt.mode))
+
+			olt, err := target.getOlt(ctx, "BBSM_OLT_0")
+			assert.NilError(t, err)
+			assert.Equal(t, olt.HardwareIdentifier, "00:00:00:00:00:01")
+			_, err = target.getOnu(ctx, "BBSM_ONU_0-1")
+			assert.NilError(t, err)
+			// the imported entries take precedence over the discovered ones
+			bp, err := target.getBp(ctx, "Default")
+			assert.NilError(t, err)
+			assert.Equal(t, bp.CIR, 1000)
+
+			// the entries of the other sources are kept
+			_, err = target.getOlt(ctx, "BBSM_OLT_1")
+			assert.NilError(t, err)
+			_, err = target.getBp(ctx, "Lab")
+			assert.NilError(t, err)
+			_, err = target.getBp(ctx, "Admin")
+			assert.NilError(t, err)
+			assert.Equal(t, len(target.listOwners()), 4)
+
+			_, err = target.getBp(ctx, "Imported")
+			assert.Equal(t, err == nil, tt.imported)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		server := httptest.NewServer(NewServer(NewStore(), nil, auth, cf).router())
+		defer server.Close()
+
+		err := ImportSnapshot(ctx, server.URL, "", bytes.NewReader(buf.Bytes()), SnapshotMerge)
+		assert.ErrorContains(t, err, "unexpected-status-code-401")
+		err = ImportSnapshot(ctx, server.URL, "secret", bytes.NewReader(buf.Bytes()), "overwrite")
+		assert.ErrorContains(t, err, "unexpected-status-code-400")
+		err = ImportSnapshot(ctx, server.URL, "secret", bytes.NewReader([]byte("{")), SnapshotMerge)
+		assert.ErrorContains(t, err, "unexpected-status-code-400")
+	})
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaticWatcher polls a list of BBSim endpoints, it is used when BBSim is not running on Kubernetes
+type StaticWatcher struct {
+	store   Storage
+	fetcher *Fetcher
+	config  *utils.ConfigFlags
+	// known contains the endpoints found in the last poll
+	known map[Owner]struct{}
+
+	lock sync.Mutex
+	// initial contains the endpoints found in the first poll, nil until then
+	initial []Owner
+}
+
+func NewStaticWatcher(store Storage, fetcher *Fetcher, cf *utils.ConfigFlags) *StaticWatcher {
+	return &StaticWatcher{
+		store:   store,
+		fetcher: fetcher,
+		config:  cf,
+		known:   map[Owner]struct{}{},
+	}
+}
+
+func (w *StaticWatcher) Watch(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// the store may have been restored with the entries of endpoints that are not configured anymore
+	for _, owner := range w.store.listOwners() {
+		if owner.Source == SourceEndpoint {
+			w.known[owner] = struct{}{}
+		}
+	}
+
+	ticker := time.NewTicker(w.config.StaticPollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll schedules a fetch for every endpoint, failing endpoints are retried by the fetcher with a backoff
+func (w *StaticWatcher) poll(ctx context.Context) {
+	current := map[Owner]struct{}{}
+	for _, endpoint := range w.endpoints(ctx) {
+		owner := Owner{Source: SourceEndpoint, Name: endpoint}
+		current[owner] = struct{}{}
+		w.fetcher.Fetch(ctx, owner, endpoint)
+	}
+
+	// endpoints that have been removed from the file don't contribute entries anymore
+	for owner := range w.known {
+		if _, ok := current[owner]; !ok {
+			w.fetcher.Forget(owner)
+			w.store.removeOwner(ctx, owner)
+		}
+	}
+	w.known = current
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.initial == nil {
+		w.initial = []Owner{}
+		for owner := range current {
+			w.initial = append(w.initial, owner)
+		}
+	}
+}
+
+func (w *StaticWatcher) Synced() bool {
+	w.lock.Lock()
+	initial := w.initial
+	w.lock.Unlock()
+	return initial != nil && w.fetcher.settled(initial)
+}
+
+// endpoints returns the endpoints provided via flags plus the ones listed in the endpoints file,
+// the file is read at every poll so that endpoints can be added and removed at runtime
+func (w *StaticWatcher) endpoints(ctx context.Context) []string {
+	endpoints := append([]string{}, w.config.StaticEndpoints...)
+
+	if w.config.StaticEndpointsFile == "" {
+		return endpoints
+	}
+
+	file, err := os.Open(w.config.StaticEndpointsFile)
+	if err != nil {
+		logger.Errorw(ctx, "cannot-open-endpoints-file", log.Fields{"file": w.config.StaticEndpointsFile, "err": err})
+		return endpoints
+	}
+	defer file.Close()
+
+	// one endpoint per line, empty lines and lines starting with # are ignored
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		endpoints = append(endpoints, line)
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Errorw(ctx, "cannot-read-endpoints-file", log.Fields{"file": w.config.StaticEndpointsFile, "err": err})
+	}
+
+	return endpoints
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitFor fails the test if condition doesn't become true within a few seconds
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newBBSimServer returns an HTTP server that mimics the BBSim SADIS endpoint
+func newBBSimServer(config SadisConfig) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/static" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(config)
+	}))
+}
+
+func Test_StaticWatcher(t *testing.T) {
+	config := SadisConfig{}
+	config.Sadis.Entries = []*SadisEntry{
+		{ID: "BBSM_OLT_0", HardwareIdentifier: "0e:fb:f6:00:00:00"},
+		{ID: "BBSM00000001-1", UniTagList: []SadisUniTag{{PonCTag: 900, PonSTag: 900}}},
+	}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 1000}}
+
+	bbsim := newBBSimServer(config)
+	defer bbsim.Close()
+
+	cf := utils.NewConfigFlags()
+	cf.StaticEndpoints = []string{bbsim.URL}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	fetcher := NewFetcher(store, cf)
+	fetcher.Start(ctx)
+
+	watcher := NewStaticWatcher(store, fetcher, cf)
+	watcher.poll(ctx)
+
+	owner := Owner{Source: SourceEndpoint, Name: bbsim.URL}
+	waitFor(t, func() bool { return store.hasOwner(owner) })
+
+	olt, err := store.getOlt(ctx, "BBSM_OLT_0")
+	assert.NilError(t, err)
+	assert.Equal(t, olt.HardwareIdentifier, "0e:fb:f6:00:00:00")
+
+	onu, err := store.getOnu(ctx, "BBSM00000001-1")
+	assert.NilError(t, err)
+	assert.Equal(t, onu.UniTagList[0].PonCTag, 900)
+
+	bp, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 1000)
+
+	// once the endpoint is not configured anymore its entries are removed
+	watcher.config.StaticEndpoints = []string{}
+	watcher.poll(ctx)
+
+	_, err = store.getOlt(ctx, "BBSM_OLT_0")
+	assert.Error(t, err, "olt-not-found-in-store")
+}
@@ -20,36 +20,508 @@ import (
 	"context"
 	"fmt"
 	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"reflect"
+	"sort"
 	"sync"
 )
 
+const (
+	// SourcePod entries are fetched from BBSim pods discovered via Kubernetes
+	SourcePod = "pod"
+	// SourceEndpoint entries are fetched from statically configured BBSim endpoints
+	SourceEndpoint = "endpoint"
+	// SourceFile entries are loaded from SADIS documents on disk
+	SourceFile = "file"
+	// SourceSnapshot entries are imported from a snapshot of the store
+	SourceSnapshot = "snapshot"
+	// SourceOverride entries are set via the admin API
+	SourceOverride = "override"
+)
+
+// Owner identifies the source that published an entry, for pods all the fields are set,
+// for endpoints and files the Name is the endpoint URL or the file path
+type Owner struct {
+	Source    string `json:"source"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+func (o Owner) String() string {
+	if o.Namespace == "" {
+		return fmt.Sprintf("%s:%s", o.Source, o.Name)
+	}
+	return fmt.Sprintf("%s:%s/%s", o.Source, o.Namespace, o.Name)
+}
+
+// priority defines which value is served when the same ID is published by different owners,
+// hand-crafted files and imported snapshots take precedence over what is discovered from BBSim,
+// and overrides set via the admin API take precedence over everything else
+func (o Owner) priority() int {
+	switch o.Source {
+	case SourceOverride:
+		return 2
+	case SourceFile, SourceSnapshot:
+		return 1
+	}
+	return 0
+}
+
+// publication is the value an owner published for an entry
+type publication struct {
+	value interface{}
+	owner Owner
+	// seq is updated every time the value changes, since is set when the owner first published the entry
+	seq   uint64
+	since uint64
+}
+
+// owners keeps track of which owners published a given entry ID, and what they published
+type owners map[string]map[Owner]publication
+
+func (o owners) add(id string, owner Owner, value interface{}, seq uint64) {
+	if _, ok := o[id]; !ok {
+		o[id] = make(map[Owner]publication)
+	}
+	since := seq
+	if p, ok := o[id][owner]; ok {
+		since = p.since
+	}
+	o[id][owner] = publication{value: value, owner: owner, seq: seq, since: since}
+}
+
+// removeEntry drops a single entry published by the owner and returns true if it was there
+func (o owners) removeEntry(id string, owner Owner) bool {
+	set, ok := o[id]
+	if !ok {
+		return false
+	}
+	if _, ok := set[owner]; !ok {
+		return false
+	}
+	delete(set, owner)
+	if len(set) == 0 {
+		delete(o, id)
+	}
+	return true
+}
+
+// remove drops the owner from every entry it published and returns the IDs of the affected entries
+func (o owners) remove(owner Owner) []string {
+	affected := []string{}
+	for id := range o {
+		if o.removeEntry(id, owner) {
+			affected = append(affected, id)
+		}
+	}
+	return affected
+}
+
+// published returns the entries published by the owner
+func (o owners) published(owner Owner) map[string]interface{} {
+	res := map[string]interface{}{}
+	for id, set := range o {
+		if p, ok := set[owner]; ok {
+			res[id] = p.value
+		}
+	}
+	return res
+}
+
+// candidates returns the publications for an entry among which the served one is chosen,
+// that is the ones from the owners with the highest priority
+func (o owners) candidates(id string) map[Owner]publication {
+	res := map[Owner]publication{}
+	highest := 0
+	for owner, p := range o[id] {
+		if len(res) == 0 || owner.priority() > highest {
+			res = map[Owner]publication{}
+			highest = owner.priority()
+		}
+		if owner.priority() == highest {
+			res[owner] = p
+		}
+	}
+	return res
+}
+
+// table contains the entries of a single type, both as published by the owners and as resolved
+type table struct {
+	kind     string
+	resolved *sync.Map
+	owners   owners
+	// servedBy contains the owner of each resolved entry
+	servedBy map[string]Owner
+}
+
+// entrySet contains all the entries published by a single owner, split by type
+type entrySet struct {
+	olts []SadisOltEntry
+	onus []SadisOnuEntryV2
+	bps  []SadisBWPEntry
+	// olt is the ID of the OLT all the ONUs are attached to, if known
+	olt string
+	// unknown contains the entries that are neither an OLT nor an ONU, they're only reported
+	unknown []SadisEntry
+}
+
+// newEntrySet splits the entries of a SADIS configuration between OLTs and ONUs
+func newEntrySet(ctx context.Context, config SadisConfig) entrySet {
+	set := entrySet{}
+	for _, entry := range config.Sadis.Entries {
+		if entry.HardwareIdentifier != "" {
+			set.olts = append(set.olts, SadisOltEntry{
+				ID:                 entry.ID,
+				HardwareIdentifier: entry.HardwareIdentifier,
+				IPAddress:          entry.IPAddress,
+				NasID:              entry.NasID,
+				UplinkPort:         entry.UplinkPort,
+				NniDhcpTrapVid:     entry.NniDhcpTrapVid,
+			})
+			continue
+		}
+		if len(entry.UniTagList) != 0 {
+			set.onus = append(set.onus, SadisOnuEntryV2{
+				ID:         entry.ID,
+				NasPortID:  entry.NasPortID,
+				CircuitID:  entry.CircuitID,
+				RemoteID:   entry.RemoteID,
+				UniTagList: entry.UniTagList,
+			})
+			continue
+		}
+		set.unknown = append(set.unknown, *entry)
+	}
+
+	for _, bp := range config.BandwidthProfile.Entries {
+		set.bps = append(set.bps, *bp)
+	}
+	return set
+}
+
+// Storage is implemented by the stores that hold the SADIS entries served to ONOS,
+// the backends only have to take care of persisting the entries published by each owner
+type Storage interface {
+	addOlt(ctx context.Context, entry SadisOltEntry, owner Owner)
+	addOnu(ctx context.Context, entry SadisOnuEntryV2, owner Owner)
+	addBp(ctx context.Context, entry SadisBWPEntry, owner Owner)
+	removeOwner(ctx context.Context, owner Owner)
+	removeEntry(ctx context.Context, kind string, id string, owner Owner) bool
+	replaceOwner(ctx context.Context, owner Owner, set entrySet)
+
+	getOlt(ctx context.Context, id string) (*SadisOltEntry, error)
+	getOnu(ctx context.Context, id string) (*SadisOnuEntryV2, error)
+	getBp(ctx context.Context, id string) (*SadisBWPEntry, error)
+	listIDs(kind string) []string
+	countEntries(kind string) int
+
+	hasOwner(owner Owner) bool
+	listOwners() []Owner
+
+	// memory returns the in-memory store in which the served entries are resolved,
+	// it provides the indexes, the snapshots, the events and the conflict and validation reports
+	memory() *Store
+
+	// Close releases the resources held by the store, making sure all the changes are persisted
+	Close() error
+}
+
+// Store keeps the entries in memory
 type Store struct {
+	// olts, onus and bps contain the resolved entries, this is what is served to ONOS
 	olts sync.Map
 	onus sync.Map
 	bps  sync.Map
+
+	ownersLock sync.Mutex
+	seq        uint64
+	oltTable   *table
+	onuTable   *table
+	bpTable    *table
+
+	conflictPolicy    string
+	namespacePriority []string
+	conflicts         map[string]*Conflict
+
+	// parents contains the OLT the ONUs published by an owner are attached to
+	parents map[Owner]string
+
+	// indexes allow to look up the served OLTs and ONUs by other fields than the ID
+	indexLock sync.RWMutex
+	indexes   map[string]index
+
+	// events records the changes to the served entries
+	events *eventLog
+
+	// validator decides which of the published entries are stored
+	validator *Validator
 }
 
 func NewStore() *Store {
-	return &Store{
-		olts: sync.Map{},
-		onus: sync.Map{},
-		bps:  sync.Map{},
+	s := &Store{
+		olts:           sync.Map{},
+		onus:           sync.Map{},
+		bps:            sync.Map{},
+		conflictPolicy: ConflictLastWins,
+		conflicts:      map[string]*Conflict{},
+		parents:        map[Owner]string{},
+		indexes:        map[string]index{},
+		events:         newEventLog(eventHistory, firstRevision()),
+		validator:      newValidator(),
+	}
+	for _, field := range IndexedFields {
+		s.indexes[field] = index{}
+	}
+	s.oltTable = &table{kind: EntryTypeOlt, resolved: &s.olts, owners: owners{}, servedBy: map[string]Owner{}}
+	s.onuTable = &table{kind: EntryTypeOnu, resolved: &s.onus, owners: owners{}, servedBy: map[string]Owner{}}
+	s.bpTable = &table{kind: EntryTypeBp, resolved: &s.bps, owners: owners{}, servedBy: map[string]Owner{}}
+	return s
+}
+
+func (s *Store) tables() []*table {
+	return []*table{s.oltTable, s.onuTable, s.bpTable}
+}
+
+// SetConflictPolicy defines which entry is served when different pods publish the same ID with different values,
+// namespacePriority is only used by the ConflictNamespacePriority policy
+func (s *Store) SetConflictPolicy(policy string, namespacePriority []string) {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	s.conflictPolicy = policy
+	s.namespacePriority = namespacePriority
+}
+
+// publish records the value published by the owner and updates the resolved entry,
+// it must be called with the ownersLock held
+func (s *Store) publish(ctx context.Context, t *table, id string, owner Owner, value interface{}) {
+	if !s.validator.admit(ctx, t.kind, id, owner, value) {
+		// the value previously published by the owner, if any, is not served anymore either
+		if t.owners.removeEntry(id, owner) {
+			s.resolve(ctx, t, id)
+		}
+		return
+	}
+	s.seq++
+	t.owners.add(id, owner, value, s.seq)
+	s.resolve(ctx, t, id)
+}
+
+// resolve updates the entry that is served for id and records the change,
+// it must be called with the ownersLock held
+func (s *Store) resolve(ctx context.Context, t *table, id string) {
+	old, served := t.resolved.Load(id)
+	oldOwner := t.servedBy[id]
+	winner, ok := s.pick(ctx, t.kind, id, t.owners.candidates(id))
+	if !ok {
+		t.resolved.Delete(id)
+		delete(t.servedBy, id)
+		s.reindex(id, old, nil)
+		if served {
+			s.events.append(ctx, Event{Type: EventDelete, Kind: t.kind, ID: id, Entry: old, Owner: oldOwner})
+		}
+		return
+	}
+	t.resolved.Store(id, winner.value)
+	t.servedBy[id] = winner.owner
+	s.reindex(id, old, winner.value)
+
+	switch {
+	case !served:
+		s.events.append(ctx, Event{Type: EventAdd, Kind: t.kind, ID: id, Entry: winner.value, Owner: winner.owner})
+	case !reflect.DeepEqual(old, winner.value) || oldOwner != winner.owner:
+		// the same entry published by a different owner (eg: a new BBSim release) is an update as well
+		s.events.append(ctx, Event{Type: EventUpdate, Kind: t.kind, ID: id, Entry: winner.value, Owner: winner.owner})
+	}
+}
+
+func (s *Store) addOlt(ctx context.Context, entry SadisOltEntry, owner Owner) {
+	logger.Debugw(ctx, "adding-olt", log.Fields{"olt": entry, "owner": owner.String()})
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	s.publish(ctx, s.oltTable, entry.ID, owner, entry)
+}
+
+func (s *Store) addOnu(ctx context.Context, entry SadisOnuEntryV2, owner Owner) {
+	logger.Debugw(ctx, "adding-onu", log.Fields{"onu": entry, "owner": owner.String()})
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	s.publish(ctx, s.onuTable, entry.ID, owner, entry)
+}
+
+func (s *Store) addBp(ctx context.Context, entry SadisBWPEntry, owner Owner) {
+	logger.Debugw(ctx, "adding-bp", log.Fields{"bp": entry, "owner": owner.String()})
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	s.publish(ctx, s.bpTable, entry.ID, owner, entry)
+}
+
+// removeOwner deletes all the entries published by the owner,
+// entries that are also published by other owners are kept
+func (s *Store) removeOwner(ctx context.Context, owner Owner) {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	removed := log.Fields{"owner": owner.String(), "uid": owner.UID}
+	for _, t := range s.tables() {
+		ids := t.owners.remove(owner)
+		for _, id := range ids {
+			s.resolve(ctx, t, id)
+		}
+		removed[t.kind+"s"] = len(ids)
+	}
+	delete(s.parents, owner)
+	s.validator.forgetOwner(owner)
+
+	logger.Infow(ctx, "removed-entries-for-owner", removed)
+}
+
+// removeEntry deletes a single entry published by the owner, it returns false if the owner didn't publish it
+func (s *Store) removeEntry(ctx context.Context, kind string, id string, owner Owner) bool {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	s.validator.forget(kind, id, owner)
+	for _, t := range s.tables() {
+		if t.kind == kind && t.owners.removeEntry(id, owner) {
+			s.resolve(ctx, t, id)
+			logger.Infow(ctx, "removed-entry-for-owner", log.Fields{"type": kind, "id": id, "owner": owner.String()})
+			return true
+		}
+	}
+	return false
+}
+
+// replaceOwner makes the entries published by the owner match set,
+// only the entries that were added, changed or removed are updated
+func (s *Store) replaceOwner(ctx context.Context, owner Owner, set entrySet) {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	olts := map[string]interface{}{}
+	for _, olt := range set.olts {
+		olts[olt.ID] = olt
 	}
+	onus := map[string]interface{}{}
+	for _, onu := range set.onus {
+		onus[onu.ID] = onu
+	}
+	bps := map[string]interface{}{}
+	for _, bp := range set.bps {
+		bps[bp.ID] = bp
+	}
+
+	if set.olt != "" {
+		s.parents[owner] = set.olt
+	} else {
+		delete(s.parents, owner)
+	}
+
+	changes := s.replace(ctx, s.oltTable, owner, olts) +
+		s.replace(ctx, s.onuTable, owner, onus) +
+		s.replace(ctx, s.bpTable, owner, bps)
+	s.validator.rejectUnknown(ctx, owner, set.unknown)
+
+	logger.Infow(ctx, "replaced-entries-for-owner", log.Fields{"owner": owner.String(), "olts": len(olts),
+		"onus": len(onus), "bps": len(bps), "changes": changes})
+}
+
+// replace applies the difference between what the owner published and entries,
+// it returns the number of changes and must be called with the ownersLock held
+func (s *Store) replace(ctx context.Context, t *table, owner Owner, entries map[string]interface{}) int {
+	changes := 0
+	current := t.owners.published(owner)
+
+	for id := range current {
+		if _, ok := entries[id]; !ok {
+			t.owners.removeEntry(id, owner)
+			s.resolve(ctx, t, id)
+			changes++
+		}
+	}
+
+	for id, value := range entries {
+		if old, ok := current[id]; ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		s.publish(ctx, t, id, owner, value)
+		changes++
+	}
+
+	// the entries the owner doesn't publish anymore are not reported either
+	s.validator.retain(t.kind, owner, entries)
+	return changes
+}
+
+// hasOwner returns true if the owner published at least one entry
+func (s *Store) hasOwner(owner Owner) bool {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	for _, t := range s.tables() {
+		for _, set := range t.owners {
+			if _, ok := set[owner]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listOwners returns all the owners that published at least one entry
+func (s *Store) listOwners() []Owner {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	found := map[Owner]struct{}{}
+	for _, t := range s.tables() {
+		for _, set := range t.owners {
+			for owner := range set {
+				found[owner] = struct{}{}
+			}
+		}
+	}
+	res := []Owner{}
+	for owner := range found {
+		res = append(res, owner)
+	}
+	return res
+}
+
+// ownerEntries returns the entries published by the owner, sorted by ID
+func (s *Store) ownerEntries(owner Owner) entrySet {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+
+	set := entrySet{olt: s.parents[owner]}
+	for _, value := range sortedByID(s.oltTable.owners.published(owner)) {
+		set.olts = append(set.olts, value.(SadisOltEntry))
+	}
+	for _, value := range sortedByID(s.onuTable.owners.published(owner)) {
+		set.onus = append(set.onus, value.(SadisOnuEntryV2))
+	}
+	for _, value := range sortedByID(s.bpTable.owners.published(owner)) {
+		set.bps = append(set.bps, value.(SadisBWPEntry))
+	}
+	return set
 }
 
-func (s *Store) addOlt(ctx context.Context, entry SadisOltEntry) {
-	logger.Debugw(ctx, "adding-olt", log.Fields{"olt": entry})
-	s.olts.Store(entry.ID, entry)
+func sortedByID(entries map[string]interface{}) []interface{} {
+	ids := []string{}
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	res := []interface{}{}
+	for _, id := range ids {
+		res = append(res, entries[id])
+	}
+	return res
 }
 
-func (s *Store) addOnu(ctx context.Context, entry SadisOnuEntryV2) {
-	logger.Debugw(ctx, "adding-onu", log.Fields{"onu": entry})
-	s.onus.Store(entry.ID, entry)
+func (s *Store) Close() error {
+	return nil
 }
 
-func (s *Store) addBp(ctx context.Context, entry SadisBWPEntry) {
-	logger.Debugw(ctx, "adding-bp", log.Fields{"bp": entry})
-	s.bps.Store(entry.ID, entry)
+func (s *Store) memory() *Store {
+	return s
 }
 
 func (s *Store) getOlt(ctx context.Context, id string) (*SadisOltEntry, error) {
@@ -44,3 +44,67 @@ func Test_getBp(t *testing.T) {
 	assert.Equal(t, loaded.ID, bp.ID)
 	assert.Equal(t, loaded.AIR, bp.AIR)
 }
+
+func Test_removeOwner(t *testing.T) {
+	store := NewStore()
+	ctx := context.TODO()
+
+	first := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+	second := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim1", UID: "uid-1"}
+
+	store.addOlt(ctx, SadisOltEntry{ID: "BBSM_OLT_0"}, first)
+	store.addOnu(ctx, SadisOnuEntryV2{ID: "BBSM00000001-1"}, first)
+	store.addBp(ctx, SadisBWPEntry{ID: "Default"}, first)
+	store.addBp(ctx, SadisBWPEntry{ID: "Default"}, second)
+
+	assert.Equal(t, len(store.listOwners()), 2)
+
+	store.removeOwner(ctx, first)
+	assert.Assert(t, !store.hasOwner(first))
+
+	_, err := store.getOlt(ctx, "BBSM_OLT_0")
+	assert.Error(t, err, "olt-not-found-in-store")
+	_, err = store.getOnu(ctx, "BBSM00000001-1")
+	assert.Error(t, err, "onu-not-found-in-store")
+
+	// the bandwidth profile is still published by the second pod
+	_, err = store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+
+	store.removeOwner(ctx, second)
+	_, err = store.getBp(ctx, "Default")
+	assert.Error(t, err, "bp-not-found-in-store")
+}
+
+func Test_replaceOwner(t *testing.T) {
+	store := NewStore()
+	ctx := context.TODO()
+
+	owner := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+
+	store.replaceOwner(ctx, owner, entrySet{
+		onus: []SadisOnuEntryV2{{ID: "BBSM00000001-1"}, {ID: "BBSM00000001-2"}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 1000}},
+	})
+	seq := store.seq
+
+	// publishing the same entries again doesn't change anything
+	store.replaceOwner(ctx, owner, entrySet{
+		onus: []SadisOnuEntryV2{{ID: "BBSM00000001-1"}, {ID: "BBSM00000001-2"}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 1000}},
+	})
+	assert.Equal(t, store.seq, seq)
+
+	store.replaceOwner(ctx, owner, entrySet{
+		onus: []SadisOnuEntryV2{{ID: "BBSM00000001-1"}},
+		bps:  []SadisBWPEntry{{ID: "Default", CIR: 2000}},
+	})
+
+	_, err := store.getOnu(ctx, "BBSM00000001-1")
+	assert.NilError(t, err)
+	_, err = store.getOnu(ctx, "BBSM00000001-2")
+	assert.Error(t, err, "onu-not-found-in-store")
+	bp, err := store.getBp(ctx, "Default")
+	assert.NilError(t, err)
+	assert.Equal(t, bp.CIR, 2000)
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsReloader loads the server certificate and the CA used to verify the clients,
+// and loads them again when the files change (eg: when cert-manager rotates them)
+type tlsReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	lock     sync.Mutex
+	modTimes map[string]time.Time
+	config   *tls.Config
+}
+
+// newTLSConfig returns the TLS configuration for the server, it fails if the files can't be loaded
+func newTLSConfig(cf *utils.ConfigFlags) (*tls.Config, error) {
+	r := &tlsReloader{
+		certFile: cf.TLSCert,
+		keyFile:  cf.TLSKey,
+		caFile:   cf.TLSClientCA,
+		modTimes: map[string]time.Time{},
+	}
+	if err := r.reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current(), nil
+		},
+	}, nil
+}
+
+func (r *tlsReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+// current returns the configuration to use for a new connection,
+// if the files changed and can't be loaded the previous configuration is kept
+func (r *tlsReloader) current() *tls.Config {
+	ctx := context.Background()
+
+	r.lock.Lock()
+	changed := false
+	for _, file := range r.files() {
+		if info, err := os.Stat(file); err == nil && !info.ModTime().Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+	r.lock.Unlock()
+
+	if changed {
+		if err := r.reload(ctx); err != nil {
+			logger.Errorw(ctx, "cannot-reload-tls-certificates", log.Fields{"err": err})
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.config
+}
+
+func (r *tlsReloader) reload(ctx context.Context) error {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.caFile != "" {
+		pem, err := ioutil.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no-certificates-found-in-%s", r.caFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.config = config
+	r.modTimes = modTimes
+	logger.Infow(ctx, "loaded-tls-certificates", log.Fields{"cert": r.certFile, "clientCa": r.caFile})
+	return nil
+}
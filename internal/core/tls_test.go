@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	der  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA if parent is nil
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NilError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NilError(t, err)
+
+	return &testCert{cert: cert, key: key, der: der, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	assert.NilError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) write(t *testing.T, certFile string, keyFile string, modTime time.Time) {
+	assert.NilError(t, ioutil.WriteFile(certFile, c.pem, 0600))
+	assert.NilError(t, ioutil.WriteFile(keyFile, c.keyPEM(t), 0600))
+	assert.NilError(t, os.Chtimes(certFile, modTime, modTime))
+	assert.NilError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func Test_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+
+	cf := utils.NewConfigFlags()
+	cf.TLSCert = filepath.Join(dir, "tls.crt")
+	cf.TLSKey = filepath.Join(dir, "tls.key")
+	cf.TLSClientCA = filepath.Join(dir, "ca.crt")
+	assert.NilError(t, ioutil.WriteFile(cf.TLSClientCA, ca.pem, 0600))
+
+	now := time.Now()
+	newTestCert(t, "server-1", ca).write(t, cf.TLSCert, cf.TLSKey, now)
+
+	config, err := newTLSConfig(cf)
+	assert.NilError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- NewServer(NewStore(), nil, nil, cf).serve(ctx, listener, config) }()
+	defer func() {
+		cancel()
+		assert.NilError(t, <-stopped)
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := newTestCert(t, "client", ca)
+
+	get := func(withCert bool) (string, error) {
+		tlsConfig := &tls.Config{RootCAs: roots}
+		if withCert {
+			tlsConfig.Certificates = []tls.Certificate{{Certificate: [][]byte{client.der}, PrivateKey: client.key}}
+		}
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+		res, err := c.Get("https://" + listener.Addr().String() + "/profiles/Default")
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		return res.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	cn, err := get(true)
+	assert.NilError(t, err)
+	assert.Equal(t, cn, "server-1")
+
+	// clients without a certificate are rejected
+	_, err = get(false)
+	assert.Assert(t, err != nil)
+
+	// the rotated certificate is used for new connections
+	newTestCert(t, "server-2", ca).write(t, cf.TLSCert, cf.TLSKey, now.Add(time.Minute))
+	cn, err = get(true)
+	assert.NilError(t, err)
+	assert.Equal(t, cn, "server-2")
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rules checked on the entries before they're stored
+const (
+	// RuleVlan checks that the VLAN IDs are between 0 and 4096 (that is ANY in ONOS)
+	RuleVlan = "vlan"
+	// RulePbit checks that the p-bit priorities are between 0 and 7
+	RulePbit = "pbit"
+	// RuleTechProfile checks that the technology profile IDs are between 64 and 255, as in VOLTHA
+	RuleTechProfile = "tp-id"
+	// RuleMac checks the format of the MAC addresses
+	RuleMac = "mac"
+	// RuleIP checks the format of the IP addresses
+	RuleIP = "ip"
+	// RuleRequired checks that the fields ONOS needs for every entry type are set
+	RuleRequired = "required"
+)
+
+// ValidationRules lists all the rules
+var ValidationRules = []string{RuleVlan, RulePbit, RuleTechProfile, RuleMac, RuleIP, RuleRequired}
+
+// actions taken when an entry violates a rule
+const (
+	// ActionWarn stores and serves the entry anyway
+	ActionWarn = "warn"
+	// ActionQuarantine doesn't serve the entry, but keeps it in the validation report so that it can be inspected
+	ActionQuarantine = "quarantine"
+	// ActionReject drops the entry
+	ActionReject = "reject"
+)
+
+// ValidationActions lists all the actions, from the weakest to the strongest
+var ValidationActions = []string{ActionWarn, ActionQuarantine, ActionReject}
+
+const (
+	minVlan        = 0
+	maxVlan        = 4096
+	maxPbit        = 7
+	minTechProfile = 64
+	maxTechProfile = 255
+	// EntryTypeUnknown is used to report the entries that are neither an OLT nor an ONU
+	EntryTypeUnknown = "unknown"
+)
+
+// Violation is a rule an entry doesn't comply with
+type Violation struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Action  string `json:"action"`
+}
+
+// ValidationResult lists the violations of an entry published by an owner
+type ValidationResult struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Owner Owner  `json:"owner"`
+	// Action is the strongest among the ones of the violations
+	Action     string      `json:"action"`
+	Violations []Violation `json:"violations"`
+	// Entry is only set for the quarantined entries
+	Entry interface{} `json:"entry,omitempty"`
+	// Since is when the violations were first detected
+	Since time.Time `json:"since"`
+}
+
+// ValidationReport is served on /validation
+type ValidationReport struct {
+	// Rules contains the action of every rule
+	Rules   map[string]string  `json:"rules"`
+	Results []ValidationResult `json:"results"`
+}
+
+type validationKey struct {
+	kind  string
+	id    string
+	owner Owner
+}
+
+// Validator checks the entries before they're stored and keeps track of the violations
+type Validator struct {
+	actions map[string]string
+
+	lock    sync.Mutex
+	results map[validationKey]*ValidationResult
+}
+
+// NewValidator creates a validator, rules are "rule=action" pairs (eg: "vlan=reject"),
+// the rules that are not listed only log a warning
+func NewValidator(rules []string) (*Validator, error) {
+	v := newValidator()
+	for _, rule := range rules {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid validation rule %s, expected rule=action", rule)
+		}
+		if _, ok := v.actions[parts[0]]; !ok {
+			return nil, fmt.Errorf("invalid validation rule %s, allowed values are: %s", parts[0],
+				strings.Join(ValidationRules, ", "))
+		}
+		if actionRank(parts[1]) < 0 {
+			return nil, fmt.Errorf("invalid validation action %s, allowed values are: %s", parts[1],
+				strings.Join(ValidationActions, ", "))
+		}
+		v.actions[parts[0]] = parts[1]
+	}
+	return v, nil
+}
+
+func newValidator() *Validator {
+	v := &Validator{actions: map[string]string{}, results: map[validationKey]*ValidationResult{}}
+	for _, rule := range ValidationRules {
+		v.actions[rule] = ActionWarn
+	}
+	return v
+}
+
+func actionRank(action string) int {
+	for i, a := range ValidationActions {
+		if a == action {
+			return i
+		}
+	}
+	return -1
+}
+
+// strongestAction returns the action to take for an entry with the given violations
+func strongestAction(violations []Violation) string {
+	action := ActionWarn
+	for _, violation := range violations {
+		if actionRank(violation.Action) > actionRank(action) {
+			action = violation.Action
+		}
+	}
+	return action
+}
+
+// check returns the rules the entry (a SadisOltEntry, SadisOnuEntryV2 or SadisBWPEntry) doesn't comply with
+func (v *Validator) check(value interface{}) []Violation {
+	violations := []Violation{}
+	add := func(rule string, field string, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Field: field, Message: fmt.Sprintf(format, args...),
+			Action: v.actions[rule]})
+	}
+	required := func(field string, set bool) {
+		if !set {
+			add(RuleRequired, field, "%s is required", field)
+		}
+	}
+	vlan := func(field string, vid int) {
+		if vid < minVlan || vid > maxVlan {
+			add(RuleVlan, field, "VLAN %d is not between %d and %d", vid, minVlan, maxVlan)
+		}
+	}
+	pbit := func(field string, priority uint8) {
+		if priority > maxPbit {
+			add(RulePbit, field, "priority %d is not between 0 and %d", priority, maxPbit)
+		}
+	}
+	mac := func(field string, address string) {
+		if hw, err := net.ParseMAC(address); err != nil || len(hw) != 6 {
+			add(RuleMac, field, "%s is not a valid MAC address", address)
+		}
+	}
+
+	switch entry := value.(type) {
+	case SadisOltEntry:
+		required("id", entry.ID != "")
+		required("hardwareIdentifier", entry.HardwareIdentifier != "")
+		required("uplinkPort", entry.UplinkPort != 0)
+		if entry.HardwareIdentifier != "" {
+			mac("hardwareIdentifier", entry.HardwareIdentifier)
+		}
+		if entry.IPAddress != "" && net.ParseIP(entry.IPAddress) == nil {
+			add(RuleIP, "ipAddress", "%s is not a valid IP address", entry.IPAddress)
+		}
+		vlan("nniDhcpTrapVid", entry.NniDhcpTrapVid)
+	case SadisOnuEntryV2:
+		required("id", entry.ID != "")
+		required("uniTagList", len(entry.UniTagList) != 0)
+		for i, tag := range entry.UniTagList {
+			prefix := fmt.Sprintf("uniTagList[%d].", i)
+			vlan(prefix+"uniTagMatch", tag.UniTagMatch)
+			vlan(prefix+"ponCTag", tag.PonCTag)
+			vlan(prefix+"ponSTag", tag.PonSTag)
+			pbit(prefix+"usPonCTagPriority", tag.UsPonCTagPriority)
+			pbit(prefix+"usPonSTagPriority", tag.UsPonSTagPriority)
+			pbit(prefix+"dsPonCTagPriority", tag.DsPonCTagPriority)
+			pbit(prefix+"dsPonSTagPriority", tag.DsPonSTagPriority)
+			required(prefix+"technologyProfileId", tag.TechnologyProfileID != 0)
+			if tag.TechnologyProfileID != 0 && (tag.TechnologyProfileID < minTechProfile || tag.TechnologyProfileID > maxTechProfile) {
+				add(RuleTechProfile, prefix+"technologyProfileId", "technology profile %d is not between %d and %d",
+					tag.TechnologyProfileID, minTechProfile, maxTechProfile)
+			}
+			if tag.ConfiguredMacAddress != "" {
+				mac(prefix+"configuredMacAddress", tag.ConfiguredMacAddress)
+			}
+		}
+	case SadisBWPEntry:
+		required("id", entry.ID != "")
+	}
+	return violations
+}
+
+// admit checks an entry the owner is publishing and records the violations,
+// it returns false if the entry must not be stored
+func (v *Validator) admit(ctx context.Context, kind string, id string, owner Owner, value interface{}) bool {
+	violations := v.check(value)
+	if len(violations) == 0 {
+		v.forget(kind, id, owner)
+		return true
+	}
+
+	action := strongestAction(violations)
+	result := ValidationResult{Type: kind, ID: id, Owner: owner, Action: action, Violations: violations, Since: time.Now()}
+	if action == ActionQuarantine {
+		result.Entry = value
+	}
+	v.record(ctx, result)
+	return action == ActionWarn
+}
+
+// rejectUnknown records the entries whose type can't be determined, they're never stored
+func (v *Validator) rejectUnknown(ctx context.Context, owner Owner, entries []SadisEntry) {
+	v.lock.Lock()
+	for key := range v.results {
+		if key.kind == EntryTypeUnknown && key.owner == owner {
+			delete(v.results, key)
+		}
+	}
+	v.lock.Unlock()
+
+	for _, entry := range entries {
+		v.record(ctx, ValidationResult{Type: EntryTypeUnknown, ID: entry.ID, Owner: owner, Action: ActionReject,
+			Violations: []Violation{{Rule: RuleRequired, Field: "hardwareIdentifier", Action: ActionReject,
+				Message: "either hardwareIdentifier (OLT) or uniTagList (ONU) is required"}},
+			Since: time.Now()})
+	}
+}
+
+// record stores the result, the violations are only logged the first time they're detected
+func (v *Validator) record(ctx context.Context, result ValidationResult) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	key := validationKey{kind: result.Type, id: result.ID, owner: result.Owner}
+	if previous, ok := v.results[key]; ok && reflect.DeepEqual(previous.Violations, result.Violations) {
+		previous.Entry = result.Entry
+		return
+	}
+	v.results[key] = &result
+
+	for _, violation := range result.Violations {
+		validationFailuresTotal.WithLabelValues(violation.Rule, violation.Action).Inc()
+	}
+	logger.Warnw(ctx, "invalid-sadis-entry", log.Fields{"type": result.Type, "id": result.ID,
+		"owner": result.Owner.String(), "action": result.Action, "violations": result.Violations})
+}
+
+// forget drops the result of an entry that is valid or not published anymore
+func (v *Validator) forget(kind string, id string, owner Owner) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.results, validationKey{kind: kind, id: id, owner: owner})
+}
+
+// forgetOwner drops the results of all the entries published by the owner
+func (v *Validator) forgetOwner(owner Owner) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for key := range v.results {
+		if key.owner == owner {
+			delete(v.results, key)
+		}
+	}
+}
+
+// retain drops the results of the entries of the given type the owner doesn't publish anymore
+func (v *Validator) retain(kind string, owner Owner, entries map[string]interface{}) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for key := range v.results {
+		if _, ok := entries[key.id]; key.kind == kind && key.owner == owner && !ok {
+			delete(v.results, key)
+		}
+	}
+}
+
+// report returns the results sorted by type, ID and owner
+func (v *Validator) report() ValidationReport {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	res := ValidationReport{Rules: map[string]string{}, Results: []ValidationResult{}}
+	for rule, action := range v.actions {
+		res.Rules[rule] = action
+	}
+	for _, result := range v.results {
+		res.Results = append(res.Results, *result)
+	}
+	sort.Slice(res.Results, func(i, j int) bool {
+		a, b := res.Results[i], res.Results[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Owner.String()+"|"+a.Owner.UID < b.Owner.String()+"|"+b.Owner.UID
+	})
+	return res
+}
+
+// SetValidator replaces the validator applied to the entries published from now on
+func (s *Store) SetValidator(v *Validator) {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	s.validator = v
+}
+
+// checkEntry returns the rules the entry doesn't comply with, without recording them
+func (s *Store) checkEntry(value interface{}) []Violation {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	return s.validator.check(value)
+}
+
+func (s *Store) validationReport() ValidationReport {
+	s.ownersLock.Lock()
+	defer s.ownersLock.Unlock()
+	return s.validator.report()
+}
+
+// serveValidation reports the entries that don't comply with the validation rules,
+// they can be filtered by action (eg: /validation?action=quarantine)
+func (s Server) serveValidation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	action := r.URL.Query().Get("action")
+	logger.Debugw(ctx, "received-validation-request", log.Fields{"action": action})
+
+	report := s.store.memory().validationReport()
+	if action != "" {
+		results := []ValidationResult{}
+		for _, result := range report.Results {
+			if result.Action == action {
+				results = append(results, result)
+			}
+		}
+		report.Results = results
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// checkOverride writes an error and returns false if the entry set via the admin API
+// violates a rule whose action is not warn, so that the client knows it's not going to be served
+func (s Server) checkOverride(ctx context.Context, w http.ResponseWriter, value interface{}) bool {
+	violations := s.store.memory().checkEntry(value)
+	if strongestAction(violations) == ActionWarn {
+		return true
+	}
+	messages := []string{}
+	for _, violation := range violations {
+		messages = append(messages, fmt.Sprintf("%s (%s)", violation.Message, violation.Field))
+	}
+	s.writeError(ctx, w, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid entry: %s.", strings.Join(messages, ", ")))
+	return false
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func validOnu(id string) SadisOnuEntryV2 {
+	return SadisOnuEntryV2{ID: id, UniTagList: []SadisUniTag{
+		{PonCTag: 900, PonSTag: 900, TechnologyProfileID: 64, ConfiguredMacAddress: "0a:00:00:00:00:01"},
+	}}
+}
+
+func Test_Validator_check(t *testing.T) {
+	v := newValidator()
+
+	tests := []struct {
+		name  string
+		entry interface{}
+		rules []string
+		field string
+	}{
+		{"valid-olt", SadisOltEntry{ID: "OLT", HardwareIdentifier: "0f:f1:ce:c4:ff:ff", IPAddress: "10.0.0.1", UplinkPort: 1048576}, nil, ""},
+		{"valid-onu", validOnu("ONU"), nil, ""},
+		{"valid-bp", SadisBWPEntry{ID: "Default"}, nil, ""},
+		{"olt-mac", SadisOltEntry{ID: "OLT", HardwareIdentifier: "BBSIM_OLT_0", UplinkPort: 1}, []string{RuleMac}, "hardwareIdentifier"},
+		{"olt-ip", SadisOltEntry{ID: "OLT", HardwareIdentifier: "0f:f1:ce:c4:ff:ff", IPAddress: "10.0.0", UplinkPort: 1}, []string{RuleIP}, "ipAddress"},
+		{"olt-uplink", SadisOltEntry{ID: "OLT", HardwareIdentifier: "0f:f1:ce:c4:ff:ff"}, []string{RuleRequired}, "uplinkPort"},
+		{"onu-no-tags", SadisOnuEntryV2{ID: "ONU"}, []string{RuleRequired}, "uniTagList"},
+		{"onu-vlan", SadisOnuEntryV2{ID: "ONU", UniTagList: []SadisUniTag{{PonCTag: 5000, TechnologyProfileID: 64}}},
+			[]string{RuleVlan}, "uniTagList[0].ponCTag"},
+		{"onu-pbit", SadisOnuEntryV2{ID: "ONU", UniTagList: []SadisUniTag{{UsPonCTagPriority: 9, TechnologyProfileID: 64}}},
+			[]string{RulePbit}, "uniTagList[0].usPonCTagPriority"},
+		{"onu-tp-id", SadisOnuEntryV2{ID: "ONU", UniTagList: []SadisUniTag{{TechnologyProfileID: 300}}},
+			[]string{RuleTechProfile}, "uniTagList[0].technologyProfileId"},
+		{"onu-no-tp-id", SadisOnuEntryV2{ID: "ONU", UniTagList: []SadisUniTag{{PonCTag: 10}}},
+			[]string{RuleRequired}, "uniTagList[0].technologyProfileId"},
+		{"onu-mac", SadisOnuEntryV2{ID: "ONU", UniTagList: []SadisUniTag{{TechnologyProfileID: 64, ConfiguredMacAddress: "0a:00:00"}}},
+			[]string{RuleMac}, "uniTagList[0].configuredMacAddress"},
+		{"bp-id", SadisBWPEntry{CIR: 1000}, []string{RuleRequired}, "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := v.check(tt.entry)
+			assert.Equal(t, len(violations), len(tt.rules))
+			for i, rule := range tt.rules {
+				assert.Equal(t, violations[i].Rule, rule)
+				assert.Equal(t, violations[i].Field, tt.field)
+				assert.Equal(t, violations[i].Action, ActionWarn)
+			}
+		})
+	}
+}
+
+func Test_NewValidator(t *testing.T) {
+	v, err := NewValidator([]string{"vlan=reject", "mac=quarantine"})
+	assert.NilError(t, err)
+	assert.Equal(t, v.actions[RuleVlan], ActionReject)
+	assert.Equal(t, v.actions[RuleMac], ActionQuarantine)
+	assert.Equal(t, v.actions[RulePbit], ActionWarn)
+
+	_, err = NewValidator([]string{"vlan"})
+	assert.Error(t, err, "invalid validation rule vlan, expected rule=action")
+	_, err = NewValidator([]string{"color=reject"})
+	assert.Error(t, err, "invalid validation rule color, allowed values are: vlan, pbit, tp-id, mac, ip, required")
+	_, err = NewValidator([]string{"vlan=drop"})
+	assert.Error(t, err, "invalid validation action drop, allowed values are: warn, quarantine, reject")
+}
+
+func Test_Validator_store(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	v, err := NewValidator([]string{"vlan=reject", "mac=quarantine"})
+	assert.NilError(t, err)
+	store.SetValidator(v)
+	pod := Owner{Source: SourcePod, Namespace: "default", Name: "bbsim0", UID: "uid-0"}
+
+	pbit := validOnu("ONU-PBIT")
+	pbit.UniTagList[0].DsPonSTagPriority = 8
+	vlan := validOnu("ONU-VLAN")
+	vlan.UniTagList[0].PonSTag = 5000
+	mac := validOnu("ONU-MAC")
+	mac.UniTagList[0].ConfiguredMacAddress = "not-a-mac"
+
+	config := SadisConfig{}
+	config.Sadis.Entries = []*SadisEntry{{ID: "ONU-UNKNOWN"}}
+	set := newEntrySet(ctx, config)
+	set.onus = []SadisOnuEntryV2{validOnu("ONU-OK"), pbit, vlan, mac}
+	store.replaceOwner(ctx, pod, set)
+
+	// warnings are served, rejected and quarantined entries are not
+	_, err = store.getOnu(ctx, "ONU-OK")
+	assert.NilError(t, err)
+	_, err = store.getOnu(ctx, "ONU-PBIT")
+	assert.NilError(t, err)
+	_, err = store.getOnu(ctx, "ONU-VLAN")
+	assert.Error(t, err, "onu-not-found-in-store")
+	_, err = store.getOnu(ctx, "ONU-MAC")
+	assert.Error(t, err, "onu-not-found-in-store")
+
+	report := store.validationReport()
+	assert.Equal(t, report.Rules[RuleVlan], ActionReject)
+	assert.Equal(t, len(report.Results), 4)
+	assert.Equal(t, report.Results[0].ID, "ONU-MAC")
+	assert.Equal(t, report.Results[0].Action, ActionQuarantine)
+	assert.Assert(t, report.Results[0].Entry != nil)
+	assert.Equal(t, report.Results[1].ID, "ONU-PBIT")
+	assert.Equal(t, report.Results[1].Action, ActionWarn)
+	assert.Equal(t, report.Results[2].ID, "ONU-VLAN")
+	assert.Equal(t, report.Results[2].Action, ActionReject)
+	assert.Assert(t, report.Results[2].Entry == nil)
+	assert.Equal(t, report.Results[3].Type, EntryTypeUnknown)
+	assert.Equal(t, report.Results[3].ID, "ONU-UNKNOWN")
+
+	// once fixed the entries are served and not reported anymore,
+	// a served entry that becomes invalid is removed
+	mac.UniTagList[0].ConfiguredMacAddress = "0a:00:00:00:00:02"
+	broken := validOnu("ONU-OK")
+	broken.UniTagList[0].PonCTag = -1
+	store.replaceOwner(ctx, pod, entrySet{onus: []SadisOnuEntryV2{broken, mac}})
+
+	_, err = store.getOnu(ctx, "ONU-MAC")
+	assert.NilError(t, err)
+	_, err = store.getOnu(ctx, "ONU-OK")
+	assert.Error(t, err, "onu-not-found-in-store")
+	report = store.validationReport()
+	assert.Equal(t, len(report.Results), 1)
+	assert.Equal(t, report.Results[0].ID, "ONU-OK")
+
+	store.removeOwner(ctx, pod)
+	assert.Equal(t, len(store.validationReport().Results), 0)
+}
+
+func Test_Validator_http(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	v, err := NewValidator([]string{"tp-id=quarantine"})
+	assert.NilError(t, err)
+	store.SetValidator(v)
+
+	onu := validOnu("ONU-TP")
+	onu.UniTagList[0].TechnologyProfileID = 1
+	store.addOnu(ctx, onu, Owner{Source: SourcePod, Name: "bbsim0"})
+
+	cf := utils.NewConfigFlags()
+	cf.AdminToken = "secret"
+	auth, err := NewAuth(cf, nil)
+	assert.NilError(t, err)
+	server := httptest.NewServer(NewServer(store, nil, auth, cf).router())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/validation?action=quarantine")
+	assert.NilError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	var report ValidationReport
+	assert.NilError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.Equal(t, len(report.Results), 1)
+	assert.Equal(t, report.Results[0].Violations[0].Field, "uniTagList[0].technologyProfileId")
+
+	// the overrides that wouldn't be served are refused
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/subscribers/ONU-TP", "secret",
+		`{"uniTagList": [{"technologyProfileId": 1}]}`), http.StatusUnprocessableEntity)
+	assert.Equal(t, adminRequest(t, http.MethodPut, server.URL+"/subscribers/ONU-TP", "secret",
+		`{"uniTagList": [{"technologyProfileId": 64}]}`), http.StatusOK)
+	_, err = store.getOnu(ctx, "ONU-TP")
+	assert.NilError(t, err)
+}
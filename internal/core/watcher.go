@@ -18,163 +18,323 @@ package core
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/opencord/bbsim-sadis-server/internal/utils"
 	"github.com/opencord/voltha-lib-go/v7/pkg/log"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
-	"net/http"
+	"k8s.io/client-go/tools/cache"
+	"net"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-const attemptLimit = 10
+// annotations that can be set on BBSim pods to customize how the SADIS configuration is fetched
+const (
+	sadisPortAnnotation   = "bbsim-sadis-server.opencord.org/port"
+	sadisSchemeAnnotation = "bbsim-sadis-server.opencord.org/scheme"
+	sadisPathAnnotation   = "bbsim-sadis-server.opencord.org/path"
+	// sadisPortName is the name of the container port on which BBSim exposes the SADIS configuration
+	sadisPortName = "sadis"
+)
+
+// Discovery finds BBSim instances and loads their SADIS configuration in the store
+type Discovery interface {
+	Watch(ctx context.Context, wg *sync.WaitGroup)
+	// Synced returns true once the instances found at startup have been loaded or have failed too many times
+	Synced() bool
+}
 
 type Watcher struct {
-	client *kubernetes.Clientset
-	store  *Store
-	config *utils.ConfigFlags
+	client    kubernetes.Interface
+	store     Storage
+	fetcher   *Fetcher
+	config    *utils.ConfigFlags
+	informers []cache.SharedIndexInformer
+
+	lock sync.Mutex
+	// initial contains the pods that were ready when the informers synced, nil until then
+	initial []Owner
 }
 
-func NewWatcher(client *kubernetes.Clientset, store *Store, cf *utils.ConfigFlags) *Watcher {
+func NewWatcher(client kubernetes.Interface, store Storage, fetcher *Fetcher, cf *utils.ConfigFlags) *Watcher {
 	return &Watcher{
-		client: client,
-		store:  store,
-		config: cf,
+		client:  client,
+		store:   store,
+		fetcher: fetcher,
+		config:  cf,
 	}
 }
 
+// Watch keeps track of the BBSim pods via shared informers, they take care of listing the existing pods
+// and of re-establishing the watch (with a new list) whenever the API server closes it
 func (w *Watcher) Watch(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	// we need to watch for PODs, services can't respond to requests if the backend is not there
-	// note that when this container starts we receive notifications for all of the existing pods
+	// create an informer for each combination of namespace and label selector,
+	// this allows to run with a namespaced Role when the namespaces are specified
+	for _, namespace := range w.config.PodNamespaces {
+		for _, selector := range w.config.PodLabelSelectors {
+			w.informers = append(w.informers, w.newPodInformer(ctx, namespace, selector))
+		}
+	}
+
+	for _, informer := range w.informers {
+		go informer.Run(ctx.Done())
+	}
 
-	watcher, err := w.client.CoreV1().Pods("").Watch(context.TODO(), metav1.ListOptions{LabelSelector: "app=bbsim"})
-	if err != nil {
-		logger.Fatalw(ctx, "error-while-watching-pods", log.Fields{"err": err})
+	synced := []cache.InformerSynced{}
+	for _, informer := range w.informers {
+		synced = append(synced, informer.HasSynced)
 	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		// the sync is only aborted when we're shutting down
+		logger.Warnw(ctx, "pod-informers-stopped-before-sync", log.Fields{})
+		return
+	}
+	logger.Infow(ctx, "pod-informers-synced", log.Fields{"informers": len(w.informers)})
 
-	ch := watcher.ResultChan()
-	for event := range ch {
+	// the store may contain the entries of pods that have been deleted while we were not running
+	w.reconcile(ctx)
 
-		pod, ok := event.Object.(*v1.Pod)
-		if !ok {
-			logger.Fatalw(ctx, "unexpected-type-while-watching-pod", log.Fields{"object": event.Object})
-		}
+	initial := []Owner{}
+	for owner := range w.readyPods() {
+		initial = append(initial, owner)
+	}
+	w.lock.Lock()
+	w.initial = initial
+	w.lock.Unlock()
+
+	// the informers deliver a delete for the pods that disappeared while the watch was down,
+	// but we still periodically reconcile the store to make sure no stale owner is left behind
+	ticker := time.NewTicker(w.config.PodResyncPeriod)
+	defer ticker.Stop()
+
+	// BBSim can change its SADIS configuration without the pod changing, so optionally re-fetch it
+	var refresh <-chan time.Time
+	if w.config.PodRefreshInterval > 0 {
+		refreshTicker := time.NewTicker(w.config.PodRefreshInterval)
+		defer refreshTicker.Stop()
+		refresh = refreshTicker.C
+	}
 
-		logger.Debugw(ctx, "received-pod-event", log.Fields{"object": event.Type, "pod": pod.Name})
-		if event.Type == watch.Deleted {
-			// TODO remove sadis entries
-			logger.Debug(ctx, "pod-has-been-removed")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reconcile(ctx)
+		case <-refresh:
+			w.refresh(ctx)
 		}
+	}
+}
 
-		if event.Type == watch.Added || event.Type == watch.Modified {
-			// fetch the sadis information and store them
-
-			// the pod is ready only if all the containers in it are ready,
-			// for now the BBSim pod only has 1 container, but things may change in the future, so keep the loop
-			ready := true
+func (w *Watcher) newPodInformer(ctx context.Context, namespace string, labelSelector string) cache.SharedIndexInformer {
+	logger.Infow(ctx, "watching-pods", log.Fields{"namespace": namespace, "labelSelector": labelSelector,
+		"fieldSelector": w.config.PodFieldSelector, "resync": w.config.PodResyncPeriod})
+
+	factory := informers.NewSharedInformerFactoryWithOptions(w.client, w.config.PodResyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+			options.FieldSelector = w.config.PodFieldSelector
+		}),
+	)
+
+	informer := factory.Core().V1().Pods().Informer()
+
+	// Error and Bookmark events are consumed by the reflector, we only log the errors that cause a re-list
+	_ = informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		watchRestartsTotal.WithLabelValues(namespace, labelSelector).Inc()
+		logger.Warnw(ctx, "pod-watch-interrupted-reestablishing", log.Fields{"namespace": namespace,
+			"labelSelector": labelSelector, "err": err})
+	})
 
-			if len(pod.Status.ContainerStatuses) == 0 {
-				// if there are no containers in the pod, then it's not ready
-				ready = false
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok {
+				w.handlePod(ctx, pod, false)
 			}
-
-			for _, containerStatus := range pod.Status.ContainerStatuses {
-				if !containerStatus.Ready {
-					// if one of the container is not ready, then the entire pod is not ready
-					ready = false
-				}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*v1.Pod)
+			if !ok {
+				return
 			}
+			if pod, ok := newObj.(*v1.Pod); ok {
+				// periodic resyncs deliver an update with the same ResourceVersion
+				w.handlePod(ctx, pod, oldPod.ResourceVersion == pod.ResourceVersion)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			w.deletePod(ctx, obj)
+		},
+	})
+
+	return informer
+}
+
+// deletePod removes the entries loaded from a pod that has been deleted
+func (w *Watcher) deletePod(ctx context.Context, obj interface{}) {
+	// if the deletion was missed while the watch was down we receive a tombstone
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		logger.Warnw(ctx, "unexpected-type-while-watching-pod", log.Fields{"object": obj})
+		return
+	}
+	logger.Debugw(ctx, "pod-has-been-removed", log.Fields{"pod": pod.Name, "namespace": pod.Namespace})
+	w.fetcher.Forget(ownerFromPod(pod))
+	w.store.removeOwner(ctx, ownerFromPod(pod))
+}
+
+// handlePod fetches the sadis information from a BBSim pod and stores them,
+// on resync the pod is only queried if nothing has been loaded from it yet (eg: a previous attempt failed)
+func (w *Watcher) handlePod(ctx context.Context, pod *v1.Pod, resync bool) {
+	ready := isPodReady(pod)
+
+	logger.Debugw(ctx, "received-event-for-bbsim-pod", log.Fields{"pod": pod.Name, "namespace": pod.Namespace,
+		"release": pod.Labels["release"], "ready": ready, "podIp": pod.Status.PodIP, "resync": resync})
+
+	owner := ownerFromPod(pod)
+
+	if resync && w.store.hasOwner(owner) {
+		return
+	}
 
-			logger.Debugw(ctx, "received-event-for-bbsim-pod", log.Fields{"pod": pod.Name, "namespace": pod.Namespace,
-				"release": pod.Labels["release"], "ready": ready, "podIp": pod.Status.PodIP})
+	// as soon as the pod is ready cache the sadis entries,
+	// if it's not ready anymore there is no point in retrying a failed fetch
+	if ready {
+		w.fetcher.Fetch(ctx, owner, w.podEndpoint(ctx, pod))
+	} else {
+		w.fetcher.Cancel(owner)
+	}
+}
 
-			// as soon as the pod is ready cache the sadis entries
-			if ready {
-				if err := w.queryPod(ctx, pod.Status.PodIP, 0); err != nil {
-					logger.Errorw(ctx, "failed-to-load-sadis-config-from-bbsim",
-						log.Fields{"pod": pod.Name, "namespace": pod.Namespace, "release": pod.Labels["release"], "err": err})
-				}
+func (w *Watcher) Synced() bool {
+	w.lock.Lock()
+	initial := w.initial
+	w.lock.Unlock()
+	return initial != nil && w.fetcher.settled(initial)
+}
+
+// readyPods returns the ready pods known to the informers
+func (w *Watcher) readyPods() map[Owner]*v1.Pod {
+	// the same pod can be matched by more than one informer
+	pods := map[Owner]*v1.Pod{}
+	for _, informer := range w.informers {
+		for _, obj := range informer.GetStore().List() {
+			if pod, ok := obj.(*v1.Pod); ok && isPodReady(pod) {
+				pods[ownerFromPod(pod)] = pod
 			}
 		}
-
 	}
+	return pods
 }
 
-func (w *Watcher) queryPod(ctx context.Context, ip string, attempt int) error {
-	endpoint := fmt.Sprintf("%s:%d", ip, w.config.BBsimSadisPort)
-	logger.Infow(ctx, "querying-service", log.Fields{"endpoint": endpoint})
+// refresh re-fetches the SADIS configuration from all the ready pods
+func (w *Watcher) refresh(ctx context.Context) {
+	pods := w.readyPods()
 
-	client := http.Client{Timeout: 5 * time.Second}
+	logger.Debugw(ctx, "refreshing-sadis-config", log.Fields{"pods": len(pods)})
 
-	res, err := client.Get(fmt.Sprintf("http://%s/v2/static", endpoint))
+	for owner, pod := range pods {
+		w.fetcher.Fetch(ctx, owner, w.podEndpoint(ctx, pod))
+	}
+}
 
-	if err != nil {
-		if attempt < attemptLimit {
-			logger.Warnw(ctx, "error-while-reading-from-service-retrying", log.Fields{"error": err.Error()})
-			// if there is an error and we have attempt left just retry later
-			time.Sleep(1 * time.Second)
-			return w.queryPod(ctx, ip, attempt+1)
+// reconcile removes from the store the entries of any pod that is not known to the informers anymore
+func (w *Watcher) reconcile(ctx context.Context) {
+	live := map[Owner]struct{}{}
+	for _, informer := range w.informers {
+		for _, obj := range informer.GetStore().List() {
+			if pod, ok := obj.(*v1.Pod); ok {
+				live[ownerFromPod(pod)] = struct{}{}
+			}
 		}
-
-		return err
 	}
 
-	if res.Body != nil {
-		defer res.Body.Close()
+	for _, owner := range w.store.listOwners() {
+		if owner.Source != SourcePod {
+			continue
+		}
+		if _, ok := live[owner]; !ok {
+			logger.Infow(ctx, "removing-entries-for-stale-owner", log.Fields{"owner": owner.String(), "uid": owner.UID})
+			w.fetcher.Forget(owner)
+			w.store.removeOwner(ctx, owner)
+		}
 	}
+}
 
-	var result SadisConfig
+// the pod is ready only if all the containers in it are ready,
+// for now the BBSim pod only has 1 container, but things may change in the future, so keep the loop
+func isPodReady(pod *v1.Pod) bool {
+	if len(pod.Status.ContainerStatuses) == 0 {
+		// if there are no containers in the pod, then it's not ready
+		return false
+	}
 
-	decoder := json.NewDecoder(res.Body)
-	if err := decoder.Decode(&result); err != nil {
-		logger.Errorw(ctx, "cannot-decode-sadis-response", log.Fields{"error": err.Error()})
-		return err
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if !containerStatus.Ready {
+			// if one of the container is not ready, then the entire pod is not ready
+			return false
+		}
 	}
+	return true
+}
 
-	logger.Debugw(ctx, "fetched-sadis-config", log.Fields{
-		"endpoint":          endpoint,
-		"entries":           len(result.Sadis.Entries),
-		"bandwidthProfiles": len(result.BandwidthProfile.Entries),
-	})
+// podEndpoint returns the base URL of the SADIS service exposed by a pod,
+// the port is taken from the annotation, the container port named "sadis" or the bbsim_sadis_port flag, in this order
+func (w *Watcher) podEndpoint(ctx context.Context, pod *v1.Pod) string {
+	port := w.config.BBsimSadisPort
 
-	for _, entry := range result.Sadis.Entries {
-		if entry.HardwareIdentifier != "" {
-			e := SadisOltEntry{
-				ID:                 entry.ID,
-				HardwareIdentifier: entry.HardwareIdentifier,
-				IPAddress:          entry.IPAddress,
-				NasID:              entry.NasID,
-				UplinkPort:         entry.UplinkPort,
-				NniDhcpTrapVid:     entry.NniDhcpTrapVid,
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == sadisPortName {
+				port = int(p.ContainerPort)
 			}
-			w.store.addOlt(ctx, e)
-			continue
 		}
-		if len(entry.UniTagList) != 0 {
-			e := SadisOnuEntryV2{
-				ID:         entry.ID,
-				NasPortID:  entry.NasPortID,
-				CircuitID:  entry.CircuitID,
-				RemoteID:   entry.RemoteID,
-				UniTagList: entry.UniTagList,
-			}
-			w.store.addOnu(ctx, e)
-			continue
+	}
+
+	if value, ok := pod.Annotations[sadisPortAnnotation]; ok {
+		if p, err := strconv.Atoi(value); err == nil && p > 0 && p < 65536 {
+			port = p
+		} else {
+			logger.Warnw(ctx, "invalid-sadis-port-annotation", log.Fields{"pod": pod.Name, "namespace": pod.Namespace,
+				"annotation": value})
 		}
-		logger.Warnw(ctx, "unknown-entity", log.Fields{"entry": entry})
 	}
 
-	for _, bp := range result.BandwidthProfile.Entries {
-		w.store.addBp(ctx, *bp)
+	scheme := "http"
+	if value, ok := pod.Annotations[sadisSchemeAnnotation]; ok && value != "" {
+		if value == "http" || value == "https" {
+			scheme = value
+		} else {
+			logger.Warnw(ctx, "invalid-sadis-scheme-annotation", log.Fields{"pod": pod.Name, "namespace": pod.Namespace,
+				"annotation": value})
+		}
 	}
 
-	logger.Infow(ctx, "stored-sadis-config", log.Fields{"endpoint": endpoint})
+	path := ""
+	if value, ok := pod.Annotations[sadisPathAnnotation]; ok && value != "" {
+		path = "/" + strings.Trim(value, "/")
+	}
 
-	return nil
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)), path)
+}
+
+func ownerFromPod(pod *v1.Pod) Owner {
+	return Owner{
+		Source:    SourcePod,
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		UID:       string(pod.UID),
+	}
 }
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2020-2024 Open Networking Foundation (ONF) and the ONF Contributors
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/opencord/bbsim-sadis-server/internal/utils"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_podEndpoint(t *testing.T) {
+	watcher := NewWatcher(nil, NewStore(), nil, utils.NewConfigFlags())
+
+	sadisPort := v1.Container{Ports: []v1.ContainerPort{{Name: "sadis", ContainerPort: 50080}}}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		containers  []v1.Container
+		expected    string
+	}{
+		{"default", nil, nil, "http://10.0.0.1:50074"},
+		{"named-port", nil, []v1.Container{sadisPort}, "http://10.0.0.1:50080"},
+		{"annotations", map[string]string{
+			sadisPortAnnotation:   "8443",
+			sadisSchemeAnnotation: "https",
+			sadisPathAnnotation:   "/bbsim/",
+		}, []v1.Container{sadisPort}, "https://10.0.0.1:8443/bbsim"},
+		{"invalid-port", map[string]string{sadisPortAnnotation: "sadis"}, nil, "http://10.0.0.1:50074"},
+		{"invalid-scheme", map[string]string{sadisSchemeAnnotation: "htps"}, nil, "http://10.0.0.1:50074"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "bbsim0", Annotations: tt.annotations},
+				Spec:       v1.PodSpec{Containers: tt.containers},
+				Status:     v1.PodStatus{PodIP: "10.0.0.1"},
+			}
+			assert.Equal(t, watcher.podEndpoint(context.TODO(), pod), tt.expected)
+		})
+	}
+}
+
+func testPod(name string, ready bool, ip string, port string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			UID:         types.UID("uid-" + name),
+			Labels:      map[string]string{"app": "bbsim"},
+			Annotations: map[string]string{sadisPortAnnotation: port},
+		},
+		Status: v1.PodStatus{
+			PodIP:             ip,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "bbsim", Ready: ready}},
+		},
+	}
+}
+
+func Test_Watcher_reconcileAfterSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live := testPod("bbsim0", false, "", "50074")
+	client := fake.NewSimpleClientset(live)
+
+	// the store contains the entries loaded before a restart
+	store := NewStore()
+	store.addBp(ctx, SadisBWPEntry{ID: "Live", CIR: 1000}, ownerFromPod(live))
+	store.addBp(ctx, SadisBWPEntry{ID: "Stale", CIR: 1000}, ownerFromPod(testPod("bbsim1", false, "", "50074")))
+	endpoint := Owner{Source: SourceEndpoint, Name: "http://10.0.0.1:50074"}
+	store.addBp(ctx, SadisBWPEntry{ID: "Endpoint", CIR: 1000}, endpoint)
+
+	cf := utils.NewConfigFlags()
+	watcher := NewWatcher(client, store, NewFetcher(store, cf), cf)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go watcher.Watch(ctx, &wg)
+
+	// the owners that are not known to the informers are removed before the watcher reports as synced
+	waitFor(t, watcher.Synced)
+	_, err := store.getBp(ctx, "Stale")
+	assert.Error(t, err, "bp-not-found-in-store")
+	_, err = store.getBp(ctx, "Live")
+	assert.NilError(t, err)
+	_, err = store.getBp(ctx, "Endpoint")
+	assert.NilError(t, err)
+
+	// the entries are removed as soon as the pod is deleted
+	assert.NilError(t, client.CoreV1().Pods("default").Delete(ctx, "bbsim0", metav1.DeleteOptions{}))
+	waitFor(t, func() bool { return !store.hasOwner(ownerFromPod(live)) })
+	assert.Assert(t, store.hasOwner(endpoint))
+
+	cancel()
+	wg.Wait()
+}
+
+func Test_Watcher_deletePod(t *testing.T) {
+	ctx := context.TODO()
+	store := NewStore()
+	cf := utils.NewConfigFlags()
+	watcher := NewWatcher(fake.NewSimpleClientset(), store, NewFetcher(store, cf), cf)
+
+	pod := testPod("bbsim0", true, "10.0.0.1", "50074")
+	store.addBp(ctx, SadisBWPEntry{ID: "Default", CIR: 1000}, ownerFromPod(pod))
+
+	// unexpected objects are ignored
+	watcher.deletePod(ctx, cache.DeletedFinalStateUnknown{Key: "default/bbsim0", Obj: "bbsim0"})
+	assert.Assert(t, store.hasOwner(ownerFromPod(pod)))
+
+	// a deletion missed while the watch was down is delivered as a tombstone
+	watcher.deletePod(ctx, cache.DeletedFinalStateUnknown{Key: "default/bbsim0", Obj: pod})
+	assert.Assert(t, !store.hasOwner(ownerFromPod(pod)))
+}
+
+func Test_Watcher_resyncSkipsLoadedPods(t *testing.T) {
+	config := SadisConfig{}
+	config.BandwidthProfile.Entries = []*SadisBWPEntry{{ID: "Default", CIR: 1000}}
+
+	var requests int32
+	bbsim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_ = json.NewEncoder(w).Encode(config)
+	}))
+	defer bbsim.Close()
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(bbsim.URL, "http://"))
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore()
+	cf := utils.NewConfigFlags()
+	fetcher := NewFetcher(store, cf)
+	fetcher.Start(ctx)
+	watcher := NewWatcher(fake.NewSimpleClientset(), store, fetcher, cf)
+
+	loaded := testPod("bbsim0", true, host, port)
+	store.addBp(ctx, SadisBWPEntry{ID: "Loaded", CIR: 1000}, ownerFromPod(loaded))
+
+	// the pods that have already been loaded are not queried on resync
+	watcher.handlePod(ctx, loaded, true)
+	fetcher.lock.Lock()
+	_, scheduled := fetcher.sources[ownerFromPod(loaded)]
+	fetcher.lock.Unlock()
+	assert.Assert(t, !scheduled)
+
+	// the ones for which nothing has been loaded yet are
+	missing := testPod("bbsim1", true, host, port)
+	watcher.handlePod(ctx, missing, true)
+	waitFor(t, func() bool { return store.hasOwner(ownerFromPod(missing)) })
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(1))
+
+	// any other update is fetched again
+	watcher.handlePod(ctx, loaded, false)
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) == 2 })
+}
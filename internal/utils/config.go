@@ -20,27 +20,197 @@ import (
 	"flag"
 	"fmt"
 	"github.com/opencord/voltha-lib-go/v7/pkg/log"
+	"strings"
+	"time"
 )
 
 const (
-	defaultLogLevel       = "WARN"
-	defaultLogFormat      = "json" // or "console"
-	defaultBBsimSadisPort = 50074
+	defaultLogLevel             = "WARN"
+	defaultLogFormat            = "json" // or "console"
+	defaultBBsimSadisPort       = 50074
+	defaultPodLabelSelector     = "app=bbsim"
+	defaultPodResyncPeriod      = 5 * time.Minute
+	defaultPollInterval         = 30 * time.Second
+	defaultSadisDirPollInterval = 10 * time.Second
+	defaultFetchWorkers         = 4
+	defaultFetchTimeout         = 5 * time.Second
+	defaultFetchBackoffBase     = 1 * time.Second
+	defaultFetchBackoffMax      = 2 * time.Minute
+	defaultReadyMaxFailures     = 10
+	defaultConflictPolicy       = "last-wins"
+	defaultStorePath            = "/var/lib/bbsim-sadis-server/store.db"
+	defaultServerURL            = "http://localhost:8080"
+	defaultListenAddress        = "0.0.0.0"
+	defaultListenPort           = 8080
+	defaultShutdownTimeout      = 10 * time.Second
+	defaultKafkaTopic           = "bbsim-sadis-server.events"
+	defaultKafkaSnapshotPeriod  = 5 * time.Minute
+	defaultAuthAnonymousRole    = "read"
+	defaultSnapshotMode         = "merge"
+	allNamespaces               = ""
 )
 
+const (
+	// DiscoveryKubernetes discovers BBSim pods via the Kubernetes API
+	DiscoveryKubernetes = "kubernetes"
+	// DiscoveryStatic polls a list of BBSim endpoints, no Kubernetes cluster is required
+	DiscoveryStatic = "static"
+)
+
+const (
+	// StoreMemory keeps the entries in memory only
+	StoreMemory = "memory"
+	// StoreBolt persists the entries in a bbolt database, so that they are served right after a restart
+	StoreBolt = "bbolt"
+)
+
+// stringList is a flag that can be specified multiple times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ";")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// split flattens a list of comma separated values, dropping the empty ones
+func (s stringList) split() []string {
+	res := []string{}
+	for _, value := range s {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				res = append(res, v)
+			}
+		}
+	}
+	return res
+}
+
 type ConfigFlags struct {
 	LogLevel       string
 	LogFormat      string
 	Kubeconfig     string
 	BBsimSadisPort int
+	// PodLabelSelectors are used to discover BBSim pods, a separate watch is started for each of them
+	PodLabelSelectors []string
+	// PodNamespaces restricts the watches to these namespaces, an empty string means all namespaces
+	PodNamespaces    []string
+	PodFieldSelector string
+	// PodResyncPeriod is how often the pod informers resync and the store is reconciled with them
+	PodResyncPeriod time.Duration
+	// PodRefreshInterval is how often the SADIS configuration is re-fetched from the ready pods, 0 disables it
+	PodRefreshInterval time.Duration
+	// DiscoveryMode is either DiscoveryKubernetes or DiscoveryStatic
+	DiscoveryMode string
+	// StaticEndpoints are the BBSim SADIS base URLs (eg: http://localhost:50074) polled in static mode
+	StaticEndpoints []string
+	// StaticEndpointsFile contains additional endpoints, one per line
+	StaticEndpointsFile string
+	StaticPollInterval  time.Duration
+	// SadisDir contains SADIS documents (*.json) that are loaded in addition to what is discovered from BBSim
+	SadisDir             string
+	SadisDirPollInterval time.Duration
+	// FetchWorkers is the number of SADIS configurations that are fetched concurrently
+	FetchWorkers int
+	FetchTimeout time.Duration
+	// FetchBackoffBase and FetchBackoffMax bound the exponential backoff applied to failing sources
+	FetchBackoffBase time.Duration
+	FetchBackoffMax  time.Duration
+	// ConflictPolicy defines which entry is served when different pods publish the same ID with different values
+	ConflictPolicy string
+	// NamespacePriority is the order in which namespaces win conflicts with the namespace-priority policy
+	NamespacePriority []string
+	// ValidationRules are "rule=action" pairs, the action taken when a published entry violates the rule
+	ValidationRules []string
+	// StoreBackend is either StoreMemory or StoreBolt
+	StoreBackend string
+	// StorePath is the bbolt database file used by the StoreBolt backend
+	StorePath string
+	// SnapshotExport and SnapshotImport are the files a snapshot of the store is written to or read from
+	// ("-" for stdout/stdin), when one of them is set the command talks to the server at ServerURL and exits
+	SnapshotExport string
+	SnapshotImport string
+	// SnapshotMode is either merge or replace
+	SnapshotMode string
+	ServerURL    string
+	// AdminToken is a bearer token that is granted the admin role
+	AdminToken string
+	// AuthTokensFile contains bearer tokens and their role, one "token:role" per line
+	AuthTokensFile string
+	// AuthBasicFile contains HTTP basic authentication users, one "username:password:role" per line
+	AuthBasicFile string
+	// AuthTokenReview validates bearer tokens with the Kubernetes TokenReview API
+	AuthTokenReview bool
+	// AuthTokenReviewAdminGroups are the groups whose users are granted the admin role via TokenReview
+	AuthTokenReviewAdminGroups []string
+	// AuthAnonymousRole is the role of the requests without credentials, either none or read
+	AuthAnonymousRole string
+	// ServerToken is the bearer token sent to the server by -snapshot_export and -snapshot_import
+	ServerToken string
+	// ReadyMinSources is the number of BBSim instances that have to be loaded for the server to be ready,
+	// if 0 the server is ready once all the instances found at startup have been fetched
+	ReadyMinSources int
+	// ReadyMaxFailures is the number of failed attempts after which an instance found at startup doesn't delay
+	// the readiness anymore, if 0 the server is not ready until all of them have been loaded
+	ReadyMaxFailures int
+	// ListenAddress and ListenPort are where the SADIS server is exposed
+	ListenAddress string
+	ListenPort    int
+	// GrpcPort is where the gRPC API is exposed on ListenAddress, 0 disables it
+	GrpcPort int
+	// TLSCert and TLSKey enable HTTPS, they're reloaded when the files change
+	TLSCert string
+	TLSKey  string
+	// TLSClientCA enables the verification of the client certificates
+	TLSClientCA string
+	// CacheTTL is how long clients can cache the entries before revalidating them, 0 means they always revalidate
+	CacheTTL time.Duration
+	// KafkaAddresses are the brokers the changes to the served entries are published to, if empty nothing is published
+	KafkaAddresses []string
+	KafkaTopic     string
+	// KafkaSnapshotPeriod is how often all the served entries are published, 0 means only at startup
+	KafkaSnapshotPeriod time.Duration
+	// ShutdownTimeout is how long the requests in progress are waited for when the server is stopped
+	ShutdownTimeout time.Duration
 }
 
 func NewConfigFlags() *ConfigFlags {
 	flags := &ConfigFlags{
-		LogLevel:       defaultLogLevel,
-		LogFormat:      defaultLogFormat,
-		Kubeconfig:     "",
-		BBsimSadisPort: defaultBBsimSadisPort,
+		LogLevel:             defaultLogLevel,
+		LogFormat:            defaultLogFormat,
+		Kubeconfig:           "",
+		BBsimSadisPort:       defaultBBsimSadisPort,
+		PodLabelSelectors:    []string{defaultPodLabelSelector},
+		PodNamespaces:        []string{allNamespaces},
+		PodFieldSelector:     "",
+		PodResyncPeriod:      defaultPodResyncPeriod,
+		DiscoveryMode:        DiscoveryKubernetes,
+		StaticEndpoints:      []string{},
+		StaticPollInterval:   defaultPollInterval,
+		SadisDir:             "",
+		SadisDirPollInterval: defaultSadisDirPollInterval,
+		FetchWorkers:         defaultFetchWorkers,
+		FetchTimeout:         defaultFetchTimeout,
+		FetchBackoffBase:     defaultFetchBackoffBase,
+		FetchBackoffMax:      defaultFetchBackoffMax,
+		ConflictPolicy:       defaultConflictPolicy,
+		NamespacePriority:    []string{},
+		ValidationRules:      []string{},
+		StoreBackend:         StoreMemory,
+		StorePath:            defaultStorePath,
+		SnapshotMode:         defaultSnapshotMode,
+		ServerURL:            defaultServerURL,
+		ListenAddress:        defaultListenAddress,
+		ListenPort:           defaultListenPort,
+		AuthAnonymousRole:    defaultAuthAnonymousRole,
+		ReadyMaxFailures:     defaultReadyMaxFailures,
+		KafkaAddresses:       []string{},
+		KafkaTopic:           defaultKafkaTopic,
+		KafkaSnapshotPeriod:  defaultKafkaSnapshotPeriod,
+		ShutdownTimeout:      defaultShutdownTimeout,
 	}
 	return flags
 }
@@ -55,8 +225,190 @@ func (cf *ConfigFlags) ParseCommandArguments() {
 	flag.StringVar(&(cf.Kubeconfig), "kubeconfig", "", "Absolute path to the kubeconfig file")
 	flag.IntVar(&(cf.BBsimSadisPort), "bbsim_sadis_port", defaultBBsimSadisPort, "The port on which BBSim exposes the Sadis server")
 
+	labelSelectors := stringList{}
+	flag.Var(&labelSelectors, "pod_label_selector",
+		fmt.Sprintf("Label selector used to discover BBSim pods, can be repeated (default %s)", defaultPodLabelSelector))
+
+	namespaces := stringList{}
+	flag.Var(&namespaces, "pod_namespace",
+		"Namespace in which to look for BBSim pods, can be repeated or comma separated (default all namespaces)")
+
+	flag.StringVar(&(cf.PodFieldSelector), "pod_field_selector", "", "Optional field selector used to discover BBSim pods")
+
+	flag.DurationVar(&(cf.PodResyncPeriod), "pod_resync_period", defaultPodResyncPeriod,
+		"How often the pod informers are resynced and the store is reconciled with the existing pods")
+
+	flag.DurationVar(&(cf.PodRefreshInterval), "pod_refresh_interval", 0,
+		"How often the SADIS configuration is re-fetched from the ready BBSim pods (default 0, disabled)")
+
+	flag.StringVar(&(cf.DiscoveryMode), "discovery_mode", DiscoveryKubernetes,
+		fmt.Sprintf("How BBSim instances are discovered (%s or %s)", DiscoveryKubernetes, DiscoveryStatic))
+
+	endpoints := stringList{}
+	flag.Var(&endpoints, "static_endpoint",
+		"BBSim SADIS base URL to poll in static mode (eg: http://localhost:50074), can be repeated or comma separated")
+
+	flag.StringVar(&(cf.StaticEndpointsFile), "static_endpoints_file", "",
+		"File containing the BBSim SADIS base URLs to poll in static mode, one per line")
+
+	flag.DurationVar(&(cf.StaticPollInterval), "static_poll_interval", defaultPollInterval,
+		"How often the BBSim endpoints are polled in static mode")
+
+	flag.StringVar(&(cf.SadisDir), "sadis_dir", "",
+		"Directory containing SADIS documents (*.json) to serve in addition to the ones discovered from BBSim")
+
+	flag.DurationVar(&(cf.SadisDirPollInterval), "sadis_dir_poll_interval", defaultSadisDirPollInterval,
+		"How often the SADIS directory is checked for changes")
+
+	flag.IntVar(&(cf.FetchWorkers), "fetch_workers", defaultFetchWorkers,
+		"Number of BBSim instances that are queried concurrently")
+
+	flag.DurationVar(&(cf.FetchTimeout), "fetch_timeout", defaultFetchTimeout,
+		"Timeout for a single request to a BBSim instance")
+
+	flag.DurationVar(&(cf.FetchBackoffBase), "fetch_backoff_base", defaultFetchBackoffBase,
+		"Initial delay before retrying a failed request to a BBSim instance, doubled at every failure")
+
+	flag.DurationVar(&(cf.FetchBackoffMax), "fetch_backoff_max", defaultFetchBackoffMax,
+		"Maximum delay before retrying a failed request to a BBSim instance")
+
+	flag.StringVar(&(cf.ConflictPolicy), "conflict_policy", defaultConflictPolicy,
+		"Entry served when different pods publish the same ID (last-wins, first-wins, reject-both or namespace-priority)")
+
+	namespacePriority := stringList{}
+	flag.Var(&namespacePriority, "namespace_priority",
+		"Namespaces in order of priority for the namespace-priority conflict policy, can be repeated or comma separated")
+
+	validationRules := stringList{}
+	flag.Var(&validationRules, "validation_rule",
+		"Action (warn, quarantine or reject) taken for the entries violating a rule (vlan, pbit, tp-id, mac, ip or required), "+
+			"eg: vlan=reject, can be repeated or comma separated (default warn)")
+
+	flag.StringVar(&(cf.StoreBackend), "store_backend", StoreMemory,
+		fmt.Sprintf("Where the entries are stored (%s or %s)", StoreMemory, StoreBolt))
+
+	flag.StringVar(&(cf.StorePath), "store_path", defaultStorePath,
+		"Database file used by the bbolt store backend")
+
+	flag.StringVar(&(cf.SnapshotExport), "snapshot_export", "",
+		"Write a snapshot of the entries served by the server at -server_url to this file (- for stdout) and exit")
+
+	flag.StringVar(&(cf.SnapshotImport), "snapshot_import", "",
+		"Load the snapshot in this file (- for stdin) in the server at -server_url and exit")
+
+	flag.StringVar(&(cf.SnapshotMode), "snapshot_mode", defaultSnapshotMode,
+		"Whether an imported snapshot is merged with the snapshots imported before or replaces them (merge or replace)")
+
+	flag.StringVar(&(cf.ServerURL), "server_url", defaultServerURL,
+		"URL of the bbsim-sadis-server used by -snapshot_export and -snapshot_import")
+
+	flag.StringVar(&(cf.AdminToken), "admin_token", "",
+		"Bearer token that is granted the admin role, required by the admin API (default empty)")
+
+	flag.StringVar(&(cf.AuthTokensFile), "auth_tokens_file", "",
+		"File containing the bearer tokens and their role (read or admin), one token:role per line")
+
+	flag.StringVar(&(cf.AuthBasicFile), "auth_basic_file", "",
+		"File containing the HTTP basic authentication users, one username:password:role per line")
+
+	flag.BoolVar(&(cf.AuthTokenReview), "auth_token_review", false,
+		"Validate bearer tokens (eg: service account tokens) via the Kubernetes TokenReview API")
+
+	adminGroups := stringList{}
+	flag.Var(&adminGroups, "auth_token_review_admin_group",
+		"Users in this group are granted the admin role via TokenReview, can be repeated or comma separated")
+
+	flag.StringVar(&(cf.AuthAnonymousRole), "auth_anonymous_role", defaultAuthAnonymousRole,
+		"Role of the requests without credentials (none or read)")
+
+	flag.StringVar(&(cf.ServerToken), "server_token", "",
+		"Bearer token used by -snapshot_export and -snapshot_import, basic credentials can be set in -server_url")
+
+	flag.IntVar(&(cf.ReadyMinSources), "ready_min_sources", 0,
+		"Report ready once this number of BBSim instances has been loaded, even if others found at startup have not been fetched yet (default 0, disabled)")
+	flag.IntVar(&(cf.ReadyMaxFailures), "ready_max_failures", defaultReadyMaxFailures,
+		"Number of failed attempts after which a BBSim instance found at startup doesn't delay the readiness anymore, 0 waits until it's loaded")
+
+	flag.StringVar(&(cf.ListenAddress), "listen_address", defaultListenAddress, "Address on which the SADIS server listens")
+
+	flag.IntVar(&(cf.ListenPort), "listen_port", defaultListenPort, "Port on which the SADIS server listens")
+
+	flag.IntVar(&(cf.GrpcPort), "grpc_port", 0, "Port on which the gRPC API listens (default disabled)")
+
+	flag.StringVar(&(cf.TLSCert), "tls_cert", "",
+		"Certificate file to serve HTTPS, it's reloaded when it changes (requires -tls_key)")
+
+	flag.StringVar(&(cf.TLSKey), "tls_key", "", "Private key file of -tls_cert")
+
+	flag.StringVar(&(cf.TLSClientCA), "tls_client_ca", "",
+		"CA certificates file used to verify the client certificates, if set clients must present a valid certificate")
+
+	flag.DurationVar(&(cf.CacheTTL), "cache_ttl", 0,
+		"Max age of the entries in the Cache-Control header, should match the SADIS cache TTL in ONOS (default 0, always revalidate)")
+
+	kafkaAddresses := stringList{}
+	flag.Var(&kafkaAddresses, "kafka_address",
+		"Kafka broker (host:port) the changes to the entries are published to, can be repeated or comma separated (default disabled)")
+
+	flag.StringVar(&(cf.KafkaTopic), "kafka_topic", defaultKafkaTopic, "Kafka topic the changes to the entries are published on")
+
+	flag.DurationVar(&(cf.KafkaSnapshotPeriod), "kafka_snapshot_period", defaultKafkaSnapshotPeriod,
+		"How often all the entries are published on Kafka, 0 to publish them only at startup")
+
+	flag.DurationVar(&(cf.ShutdownTimeout), "shutdown_timeout", defaultShutdownTimeout,
+		"How long the requests in progress are waited for on SIGTERM before closing the connections")
+
 	flag.Parse()
 
+	if cf.FetchWorkers < 1 {
+		panic("fetch_workers must be at least 1")
+	}
+
+	if len(labelSelectors) > 0 {
+		cf.PodLabelSelectors = labelSelectors
+	}
+
+	if len(namespaces) > 0 {
+		cf.PodNamespaces = namespaces.split()
+		if len(cf.PodNamespaces) == 0 {
+			cf.PodNamespaces = []string{allNamespaces}
+		}
+	}
+
+	cf.StaticEndpoints = endpoints.split()
+	cf.NamespacePriority = namespacePriority.split()
+	cf.ValidationRules = validationRules.split()
+	cf.AuthTokenReviewAdminGroups = adminGroups.split()
+	cf.KafkaAddresses = kafkaAddresses.split()
+
+	if cf.AuthAnonymousRole != "none" && cf.AuthAnonymousRole != "read" {
+		panic("auth_anonymous_role is invalid, allowed values are: none, read")
+	}
+
+	if cf.DiscoveryMode != DiscoveryKubernetes && cf.DiscoveryMode != DiscoveryStatic {
+		panic(fmt.Sprintf("discovery_mode is invalid, allowed values are: %s, %s", DiscoveryKubernetes, DiscoveryStatic))
+	}
+
+	if cf.StoreBackend != StoreMemory && cf.StoreBackend != StoreBolt {
+		panic(fmt.Sprintf("store_backend is invalid, allowed values are: %s, %s", StoreMemory, StoreBolt))
+	}
+
+	if cf.SnapshotMode != "merge" && cf.SnapshotMode != "replace" {
+		panic("snapshot_mode is invalid, allowed values are: merge, replace")
+	}
+
+	if cf.SnapshotExport != "" && cf.SnapshotImport != "" {
+		panic("snapshot_export and snapshot_import can't be used together")
+	}
+
+	if (cf.TLSCert == "") != (cf.TLSKey == "") {
+		panic("tls_cert and tls_key must be provided together")
+	}
+
+	if cf.TLSClientCA != "" && cf.TLSCert == "" {
+		panic("tls_client_ca requires tls_cert and tls_key")
+	}
+
 	if *logFormat != log.CONSOLE && *logFormat != log.JSON {
 		panic(fmt.Sprintf("log_format is invalid, allowed values are: %s, %s", log.JSON, log.CONSOLE))
 	}